@@ -0,0 +1,186 @@
+// Package mantle turns a directory of markdown posts into a static JSON API.
+//
+// Build runs the whole pipeline and writes the result below OUTPUT_DIR, while
+// LoadPosts and Handler serve the same API from memory. The stages are
+// available individually from the config, content, process, output and deploy
+// packages.
+package mantle
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+
+	"github.com/tech-arch1tect/mantle/cache"
+	"github.com/tech-arch1tect/mantle/config"
+	"github.com/tech-arch1tect/mantle/content"
+	"github.com/tech-arch1tect/mantle/deploy"
+	"github.com/tech-arch1tect/mantle/output"
+	"github.com/tech-arch1tect/mantle/process"
+)
+
+// Result describes a completed build.
+type Result struct {
+	// Posts holds the processed posts that were written.
+	Posts process.ProcessedPosts
+	// Written counts output files that were created or changed.
+	Written int
+	// Unchanged counts output files left untouched because the previous
+	// build produced identical content.
+	Unchanged int
+	// Removed counts stale files from previous builds that were deleted.
+	Removed int
+	// Issues lists the content problems that were worked around.
+	Issues []content.Issue
+}
+
+// StrictError is returned by Build in strict mode when loading the content
+// reported any issues. Nothing is written in that case.
+type StrictError struct {
+	Issues []content.Issue
+}
+
+func (e *StrictError) Error() string {
+	return fmt.Sprintf("strict mode: %d content issue(s) found", len(e.Issues))
+}
+
+// Build runs the whole generation pipeline once, writing the output below
+// cfg.OutputDir. Cancelling ctx stops the build between stages; with
+// ATOMIC_OUTPUT enabled the published output is then left as it was. With
+// STRICT enabled, content issues fail the build with a *StrictError.
+func Build(ctx context.Context, cfg *config.Config) (*Result, error) {
+	logger := log.New(os.Stdout, "[Mantle] ", log.LstdFlags)
+
+	var stager *deploy.BuildStager
+	if cfg.AtomicOutput {
+		stager = deploy.NewBuildStager(cfg)
+		stagingDir, err := stager.Prepare()
+		if err != nil {
+			return nil, fmt.Errorf("failed to prepare staging directory: %w", err)
+		}
+
+		stagingCfg := *cfg
+		stagingCfg.OutputDir = stagingDir
+		cfg = &stagingCfg
+	}
+
+	buildCache, err := cache.LoadBuildCache(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load build cache: %w", err)
+	}
+	writer := output.NewOutputWriter(cfg.OutputDir, buildCache)
+
+	processedPosts, issues, err := loadPosts(ctx, cfg, buildCache, logger)
+	if cfg.Strict && len(issues) > 0 {
+		return nil, &StrictError{Issues: issues}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	outputProcessor := output.NewOutputProcessor(cfg, buildCache, writer)
+	if err := outputProcessor.Process(processedPosts); err != nil {
+		return nil, fmt.Errorf("failed to process output: %w", err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	webServerGenerator := deploy.NewWebServerGenerator(cfg, writer)
+	if err := webServerGenerator.Generate(); err != nil {
+		return nil, fmt.Errorf("failed to generate webserver files: %w", err)
+	}
+
+	if cfg.GenerateSwagger {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		swaggerGenerator := NewSwaggerGenerator(cfg, writer)
+		if err := swaggerGenerator.Generate(); err != nil {
+			return nil, fmt.Errorf("failed to generate OpenAPI specification: %w", err)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	removed, err := writer.PruneStale()
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove stale output: %w", err)
+	}
+	if removed > 0 {
+		logger.Printf("Removed %d stale file(s) from previous builds", removed)
+	}
+
+	if err := buildCache.Save(); err != nil {
+		return nil, fmt.Errorf("failed to save build cache: %w", err)
+	}
+
+	if stager != nil {
+		if err := stager.Commit(); err != nil {
+			return nil, fmt.Errorf("failed to publish build: %w", err)
+		}
+	}
+
+	written, unchanged := writer.Stats()
+	return &Result{
+		Posts:     processedPosts,
+		Written:   written,
+		Unchanged: unchanged,
+		Removed:   removed,
+		Issues:    issues,
+	}, nil
+}
+
+// LoadPosts loads, renders and processes the posts in cfg.ContentDir without
+// writing any output, for use with Handler.
+func LoadPosts(ctx context.Context, cfg *config.Config) (process.ProcessedPosts, error) {
+	logger := log.New(os.Stdout, "[Mantle] ", log.LstdFlags)
+	processedPosts, _, err := loadPosts(ctx, cfg, cache.NewMemoryBuildCache(cfg), logger)
+	return processedPosts, err
+}
+
+func loadPosts(ctx context.Context, cfg *config.Config, buildCache *cache.BuildCache, logger *log.Logger) (process.ProcessedPosts, []content.Issue, error) {
+	publishFilter, err := content.NewPublishFilter(cfg)
+	if err != nil {
+		return process.ProcessedPosts{}, nil, fmt.Errorf("failed to determine build clock: %w", err)
+	}
+
+	// WebP variants are always lossless, so the quality only applies to JPEG.
+	if cfg.ProcessImages && cfg.Source("image_quality") != config.SourceDefault &&
+		!slices.Contains(config.SplitList(cfg.ImageFormats), config.ImageFormatJPEG) {
+		logger.Printf("Warning: IMAGE_QUALITY has no effect because WebP variants are lossless and IMAGE_FORMATS has no jpeg")
+	}
+
+	loader := content.NewPostLoader(cfg, publishFilter, buildCache)
+	posts, err := loader.LoadAll()
+	if err != nil {
+		return process.ProcessedPosts{}, nil, fmt.Errorf("failed to load posts: %w", err)
+	}
+	issues := loader.Issues()
+
+	if len(posts) == 0 {
+		return process.ProcessedPosts{}, issues, fmt.Errorf("no posts found in %s", cfg.ContentDir)
+	}
+	logger.Printf("Loaded %d post(s) with %d issue(s)", len(posts), len(issues))
+
+	if err := ctx.Err(); err != nil {
+		return process.ProcessedPosts{}, issues, err
+	}
+	renderer := content.NewMarkdownRenderer(cfg, buildCache)
+	posts, err = renderer.Render(posts)
+	if err != nil {
+		return process.ProcessedPosts{}, issues, fmt.Errorf("failed to render posts: %w", err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return process.ProcessedPosts{}, issues, err
+	}
+	processor := process.NewPostProcessor(cfg, buildCache)
+	return processor.Process(posts), issues, nil
+}
@@ -0,0 +1,248 @@
+// Package cache records content hashes between builds so that unchanged
+// sources, renders, aggregates and output files can be reused.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/tech-arch1tect/mantle/config"
+	"github.com/tech-arch1tect/mantle/internal/fsutil"
+)
+
+const buildManifestVersion = 2
+
+type BuildManifest struct {
+	Version    int                       `json:"version"`
+	ConfigHash string                    `json:"configHash"`
+	Sources    map[string]SourceEntry    `json:"sources"`
+	Renders    map[string]RenderEntry    `json:"renders"`
+	Aggregates map[string]AggregateEntry `json:"aggregates"`
+	Outputs    map[string]string         `json:"outputs"`
+}
+
+type SourceEntry struct {
+	Hash string          `json:"hash"`
+	Post json.RawMessage `json:"post"`
+}
+
+type RenderEntry struct {
+	HTML          string   `json:"html"`
+	ExcerptHTML   string   `json:"excerptHtml"`
+	CodeLanguages []string `json:"codeLanguages,omitempty"`
+}
+
+type AggregateEntry struct {
+	InputHash string          `json:"inputHash"`
+	Result    json.RawMessage `json:"result,omitempty"`
+}
+
+type BuildCache struct {
+	path     string
+	reuse    bool
+	logger   *log.Logger
+	previous BuildManifest
+	current  BuildManifest
+}
+
+func newBuildManifest(configHash string) BuildManifest {
+	return BuildManifest{
+		Version:    buildManifestVersion,
+		ConfigHash: configHash,
+		Sources:    make(map[string]SourceEntry),
+		Renders:    make(map[string]RenderEntry),
+		Aggregates: make(map[string]AggregateEntry),
+		Outputs:    make(map[string]string),
+	}
+}
+
+func LoadBuildCache(cfg *config.Config) (*BuildCache, error) {
+	configHash := contentConfigHash(cfg)
+	bc := &BuildCache{
+		path:     filepath.Join(cfg.OutputDir, ".mantle", "manifest.json"),
+		reuse:    cfg.BuildCache,
+		logger:   log.New(os.Stdout, "[BuildCache] ", log.LstdFlags),
+		previous: newBuildManifest(""),
+		current:  newBuildManifest(configHash),
+	}
+
+	data, err := os.ReadFile(bc.path)
+	if errors.Is(err, os.ErrNotExist) {
+		bc.logger.Println("No build manifest found, performing a full build")
+		return bc, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read build manifest: %w", err)
+	}
+
+	var previous BuildManifest
+	if err := json.Unmarshal(data, &previous); err != nil || previous.Version != buildManifestVersion {
+		bc.logger.Println("Build manifest is unreadable or outdated, performing a full build")
+		return bc, nil
+	}
+
+	// Outputs are always tracked so they can be compared against, but cached
+	// content is only reused when it was produced with the same settings.
+	bc.previous.Outputs = previous.Outputs
+	if previous.ConfigHash != configHash {
+		bc.logger.Println("Configuration changed since the last build, performing a full build")
+		return bc, nil
+	}
+	bc.previous = previous
+
+	if bc.reuse {
+		bc.logger.Printf("Loaded build manifest with %d source(s)", len(previous.Sources))
+	}
+	return bc, nil
+}
+
+// NewMemoryBuildCache creates a cache that starts empty and is never saved,
+// for builds that should not read or leave anything on disk.
+func NewMemoryBuildCache(cfg *config.Config) *BuildCache {
+	return &BuildCache{
+		logger:   log.New(os.Stdout, "[BuildCache] ", log.LstdFlags),
+		previous: newBuildManifest(""),
+		current:  newBuildManifest(contentConfigHash(cfg)),
+	}
+}
+
+// LookupSource decodes the post parsed from the source at path into post when
+// the source is unchanged since the last build.
+func (bc *BuildCache) LookupSource(path, hash string, post interface{}) bool {
+	entry, ok := bc.previous.Sources[path]
+	if !bc.reuse || !ok || entry.Hash != hash {
+		return false
+	}
+	if err := json.Unmarshal(entry.Post, post); err != nil {
+		return false
+	}
+	bc.current.Sources[path] = SourceEntry{Hash: hash, Post: entry.Post}
+	return true
+}
+
+func (bc *BuildCache) StoreSource(path, hash string, post interface{}) error {
+	data, err := json.Marshal(post)
+	if err != nil {
+		return fmt.Errorf("failed to encode source %s: %w", path, err)
+	}
+	bc.current.Sources[path] = SourceEntry{Hash: hash, Post: data}
+	return nil
+}
+
+func (bc *BuildCache) LookupRender(key string) (RenderEntry, bool) {
+	entry, ok := bc.previous.Renders[key]
+	if !bc.reuse || !ok {
+		return RenderEntry{}, false
+	}
+	bc.current.Renders[key] = entry
+	return entry, true
+}
+
+func (bc *BuildCache) StoreRender(key string, entry RenderEntry) {
+	bc.current.Renders[key] = entry
+}
+
+// LookupAggregate decodes the cached result of an aggregate computation into
+// result when the hash of its inputs is unchanged since the last build.
+func (bc *BuildCache) LookupAggregate(name, inputHash string, result interface{}) bool {
+	entry, ok := bc.previous.Aggregates[name]
+	if !bc.reuse || !ok || entry.InputHash != inputHash {
+		return false
+	}
+	if result != nil {
+		if err := json.Unmarshal(entry.Result, result); err != nil {
+			return false
+		}
+	}
+	bc.current.Aggregates[name] = entry
+	return true
+}
+
+func (bc *BuildCache) StoreAggregate(name, inputHash string, result interface{}) error {
+	entry := AggregateEntry{InputHash: inputHash}
+	if result != nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("failed to encode aggregate %s: %w", name, err)
+		}
+		entry.Result = data
+	}
+	bc.current.Aggregates[name] = entry
+	return nil
+}
+
+// PreviousOutput returns the content hash the previous build recorded for the
+// output file at path, if it may be reused.
+func (bc *BuildCache) PreviousOutput(path string) (string, bool) {
+	if !bc.reuse {
+		return "", false
+	}
+	hash, ok := bc.previous.Outputs[path]
+	return hash, ok
+}
+
+func (bc *BuildCache) RecordOutput(path, hash string) {
+	bc.current.Outputs[path] = hash
+}
+
+// StaleOutputs lists output files recorded by the previous build that have not
+// been recorded by this one.
+func (bc *BuildCache) StaleOutputs() []string {
+	var stale []string
+	for path := range bc.previous.Outputs {
+		if _, ok := bc.current.Outputs[path]; !ok {
+			stale = append(stale, path)
+		}
+	}
+	sort.Strings(stale)
+	return stale
+}
+
+func (bc *BuildCache) Save() error {
+	data, err := json.Marshal(bc.current)
+	if err != nil {
+		return fmt.Errorf("failed to encode build manifest: %w", err)
+	}
+
+	if err := fsutil.WriteFileAtomic(bc.path, data); err != nil {
+		return fmt.Errorf("failed to write build manifest: %w", err)
+	}
+
+	bc.logger.Printf("Saved build manifest with %d source(s) and %d output(s)", len(bc.current.Sources), len(bc.current.Outputs))
+	return nil
+}
+
+func contentConfigHash(cfg *config.Config) string {
+	return HashJSON([]interface{}{
+		buildManifestVersion,
+		cfg.DateFormat,
+		cfg.AverageWordsPerMinute,
+		cfg.CategoryFromPath,
+		cfg.RendersHTML(),
+		cfg.HighlightCode,
+		cfg.HighlightStyle,
+		cfg.TocMinDepth,
+		cfg.TocMaxDepth,
+	})
+}
+
+func HashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// HashJSON hashes the JSON encoding of value.
+func HashJSON(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return HashBytes(data)
+}
@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/tech-arch1tect/mantle/config"
+)
+
+func TestLoadBuildCacheConfigChange(t *testing.T) {
+	tests := []struct {
+		name       string
+		change     func(cfg *config.Config)
+		invalidate bool
+	}{
+		{"unchanged", func(cfg *config.Config) {}, false},
+		{"date format", func(cfg *config.Config) { cfg.DateFormat = "02/01/2006" }, true},
+		{"category from path", func(cfg *config.Config) { cfg.CategoryFromPath = true }, true},
+		{"html output", func(cfg *config.Config) { cfg.ContentFormat = config.ContentFormatHTML }, true},
+		{"highlight style", func(cfg *config.Config) { cfg.HighlightStyle = "dracula" }, true},
+		{"toc depth", func(cfg *config.Config) { cfg.TocMaxDepth = 2 }, true},
+		{"posts per page", func(cfg *config.Config) { cfg.PostsPerPage = 3 }, false},
+	}
+	for _, tt := range tests {
+		cfg := config.NewConfig()
+		cfg.OutputDir = t.TempDir()
+
+		previous, err := LoadBuildCache(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := previous.StoreSource("post.md", "hash", "parsed"); err != nil {
+			t.Fatal(err)
+		}
+		previous.StoreRender("key", RenderEntry{HTML: "<p>rendered</p>"})
+		if err := previous.StoreAggregate("tags", "inputs", nil); err != nil {
+			t.Fatal(err)
+		}
+		previous.RecordOutput("public_html/index.json", "output")
+		if err := previous.Save(); err != nil {
+			t.Fatal(err)
+		}
+
+		tt.change(cfg)
+		bc, err := LoadBuildCache(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var post string
+		reused := map[string]bool{
+			"source":    bc.LookupSource("post.md", "hash", &post),
+			"aggregate": bc.LookupAggregate("tags", "inputs", nil),
+		}
+		_, reused["render"] = bc.LookupRender("key")
+		for kind, ok := range reused {
+			if ok == tt.invalidate {
+				t.Errorf("%s: cached %s reused = %t, want %t", tt.name, kind, ok, !tt.invalidate)
+			}
+		}
+
+		// Outputs are compared by content, so they are kept either way.
+		if hash, ok := bc.PreviousOutput("public_html/index.json"); !ok || hash != "output" {
+			t.Errorf("%s: previous output = %q, %t, want it kept", tt.name, hash, ok)
+		}
+	}
+}
@@ -0,0 +1,425 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tech-arch1tect/mantle"
+	"github.com/tech-arch1tect/mantle/config"
+	"github.com/tech-arch1tect/mantle/content"
+	"github.com/tech-arch1tect/mantle/deploy"
+	"github.com/tech-arch1tect/mantle/lint"
+	"github.com/tech-arch1tect/mantle/server"
+)
+
+func main() {
+	logger := log.New(os.Stdout, "[Mantle] ", log.LstdFlags)
+
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "serve":
+			serve(logger, os.Args[2:])
+			return
+		case "watch":
+			watch(logger, os.Args[2:])
+			return
+		case "config":
+			configCommand(logger, os.Args[2:])
+			return
+		case "lint":
+			lintCommand(logger, os.Args[2:])
+			return
+		}
+	}
+
+	var generateOpenAPIOnly, rollback bool
+	var reportPath string
+	flag.BoolVar(&generateOpenAPIOnly, "openapi-only", false, "Generate only the OpenAPI specification and exit")
+	flag.BoolVar(&generateOpenAPIOnly, "generate-openapi-only", false, "Generate only the OpenAPI specification and exit (alias)")
+	flag.BoolVar(&rollback, "rollback", false, "Switch OUTPUT_DIR back to the previously published build and exit")
+	flag.StringVar(&reportPath, "report", "", "Write a JSON report of content issues to this file")
+	configOpts := registerConfigFlags(flag.CommandLine)
+	buildOpts := registerBuildFlags(flag.CommandLine)
+	flag.Parse()
+	// The OpenAPI specification does not depend on content, so it can be
+	// generated before CONTENT_DIR exists.
+	configOpts.settingsOnly = generateOpenAPIOnly
+
+	cfg, err := configOpts.load(buildOpts)
+	if err != nil {
+		logger.Fatalf("Failed to load config: %v", err)
+	}
+	if file := cfg.File(); file != "" {
+		logger.Printf("Using config file %s", file)
+	}
+	logger.Printf("Loaded configuration: %s", cfg)
+
+	if generateOpenAPIOnly {
+		logger.Println("Generating OpenAPI specification only...")
+
+		swaggerGenerator := mantle.NewSwaggerGenerator(cfg, nil)
+		if err := swaggerGenerator.Generate(); err != nil {
+			logger.Fatalf("Failed to generate OpenAPI specification: %v", err)
+		}
+
+		logger.Println("OpenAPI specification generated successfully")
+		return
+	}
+
+	if rollback {
+		if _, err := deploy.NewBuildStager(cfg).Rollback(); err != nil {
+			logger.Fatalf("Failed to roll back: %v", err)
+		}
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	result, err := mantle.Build(ctx, cfg)
+
+	var strictErr *mantle.StrictError
+	var issues []content.Issue
+	switch {
+	case result != nil:
+		issues = result.Issues
+	case errors.As(err, &strictErr):
+		issues = strictErr.Issues
+	}
+	if reportPath != "" && (result != nil || strictErr != nil) {
+		if err := mantle.NewReport(issues, cfg.Strict).WriteFile(reportPath); err != nil {
+			logger.Fatalf("Failed to write report: %v", err)
+		}
+		logger.Printf("Wrote report to %s", reportPath)
+	}
+
+	if err != nil {
+		if strictErr != nil {
+			for _, issue := range strictErr.Issues {
+				logger.Printf("  %s", issue)
+			}
+		}
+		logger.Fatalf("Build failed: %v", err)
+	}
+
+	if len(issues) > 0 {
+		logger.Printf("Mantle completed with %d content issue(s)", len(issues))
+		return
+	}
+	logger.Println("Mantle completed successfully")
+}
+
+// configOptions holds the command line flags that choose the config file and
+// override where content is read from and output written to.
+type configOptions struct {
+	file       string
+	contentDir string
+	outputDir  string
+	settings   settingFlags
+	// settingsOnly skips the checks on CONTENT_DIR and OUTPUT_DIR.
+	settingsOnly bool
+}
+
+func registerConfigFlags(flags *flag.FlagSet) *configOptions {
+	options := &configOptions{}
+	flags.StringVar(&options.file, "config", "", "Config file to load instead of mantle.yaml, mantle.yml or mantle.toml in the working directory")
+	flags.StringVar(&options.contentDir, "content-dir", "", "Directory containing markdown files (overrides CONTENT_DIR)")
+	flags.StringVar(&options.outputDir, "output-dir", "", "Directory for generated files (overrides OUTPUT_DIR)")
+	flags.Var(&options.settings, "set", "Override any setting as KEY=VALUE, e.g. posts_per_page=20 or SITE_URL=https://example.com (repeatable)")
+	return options
+}
+
+// settingFlags collects repeated --set KEY=VALUE flags. Keys are the config
+// file keys, or the environment variable names in any case.
+type settingFlags map[string]string
+
+func (s *settingFlags) String() string {
+	return ""
+}
+
+func (s *settingFlags) Set(value string) error {
+	key, setting, ok := strings.Cut(value, "=")
+	if !ok || strings.TrimSpace(key) == "" {
+		return fmt.Errorf("expected KEY=VALUE, got %q", value)
+	}
+	if *s == nil {
+		*s = make(settingFlags)
+	}
+	(*s)[strings.ToLower(strings.TrimSpace(key))] = setting
+	return nil
+}
+
+// load reads the config file and environment, then applies the flags on top,
+// so that flags take precedence over the environment and the environment over
+// the file. Validation problems are returned together with the loaded config.
+func (o *configOptions) load(build *buildOptions) (*config.Config, error) {
+	cfg := config.NewConfig()
+	if err := cfg.LoadFrom(o.file); err != nil && !errors.As(err, new(*config.ValidationError)) {
+		return nil, err
+	}
+
+	// The dedicated flags take precedence over --set for the same setting.
+	overrides := make(map[string]string)
+	for key, value := range o.settings {
+		overrides[key] = value
+	}
+	if o.contentDir != "" {
+		overrides["content_dir"] = o.contentDir
+	}
+	if o.outputDir != "" {
+		overrides["output_dir"] = o.outputDir
+	}
+	if build != nil {
+		build.addOverrides(overrides)
+	}
+
+	for key, value := range overrides {
+		if err := cfg.Set(key, value, config.SourceFlag); err != nil {
+			return nil, err
+		}
+	}
+	if o.settingsOnly {
+		return cfg, cfg.ValidateSettings()
+	}
+	return cfg, cfg.Validate()
+}
+
+// buildOptions holds the command line flags that override build settings.
+type buildOptions struct {
+	buildDrafts bool
+	buildFuture bool
+	buildTime   string
+	noCache     bool
+	strict      bool
+}
+
+func registerBuildFlags(flags *flag.FlagSet) *buildOptions {
+	options := &buildOptions{}
+	flags.BoolVar(&options.buildDrafts, "build-drafts", false, "Include posts marked as drafts")
+	flags.BoolVar(&options.buildFuture, "build-future", false, "Include posts with a publish date in the future")
+	flags.StringVar(&options.buildTime, "build-time", "", "Override the build clock (RFC 3339 or DATE_FORMAT)")
+	flags.BoolVar(&options.noCache, "no-cache", false, "Ignore the build cache and rebuild everything")
+	flags.BoolVar(&options.strict, "strict", false, "Fail the build if any content file has problems")
+	return options
+}
+
+func (o *buildOptions) addOverrides(overrides map[string]string) {
+	if o.buildDrafts {
+		overrides["build_drafts"] = "true"
+	}
+	if o.buildFuture {
+		overrides["build_future"] = "true"
+	}
+	if o.buildTime != "" {
+		overrides["build_time"] = o.buildTime
+	}
+	if o.noCache {
+		overrides["build_cache"] = "false"
+	}
+	if o.strict {
+		overrides["strict"] = "true"
+	}
+}
+
+func serve(logger *log.Logger, args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", ":8080", "Address to listen on")
+	configOpts := registerConfigFlags(flags)
+	_ = flags.Parse(args)
+
+	cfg, err := configOpts.load(nil)
+	if err != nil {
+		logger.Fatalf("Failed to load config: %v", err)
+	}
+
+	publicDir := filepath.Join(deploy.PublishedDir(cfg), "public_html")
+	if info, err := os.Stat(publicDir); err != nil || !info.IsDir() {
+		logger.Fatalf("No generated output found in %s, run mantle first", publicDir)
+	}
+
+	httpServer := &http.Server{
+		Addr:    *addr,
+		Handler: server.NewRouter(cfg, os.DirFS(publicDir)),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logger.Printf("Serving %s on %s", publicDir, *addr)
+	if err := server.ListenAndServe(ctx, httpServer); err != nil {
+		logger.Fatalf("Failed to serve: %v", err)
+	}
+}
+
+func watch(logger *log.Logger, args []string) {
+	flags := flag.NewFlagSet("watch", flag.ExitOnError)
+	addr := flags.String("addr", ":8080", "Address to listen on")
+	poll := flags.Bool("poll", false, "Poll CONTENT_DIR for changes instead of using filesystem notifications")
+	pollInterval := flags.Duration("poll-interval", time.Second, "How often to poll CONTENT_DIR when polling")
+	debounce := flags.Duration("debounce", 300*time.Millisecond, "How long changes must settle before rebuilding")
+	configOpts := registerConfigFlags(flags)
+	buildOpts := registerBuildFlags(flags)
+	_ = flags.Parse(args)
+
+	cfg, err := configOpts.load(buildOpts)
+	if err != nil {
+		logger.Fatalf("Failed to load config: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	broker := server.NewEventBroker(cfg)
+	var signatures map[string]string
+	rebuild := func() {
+		result, err := mantle.Build(ctx, cfg)
+		if err != nil {
+			logger.Printf("Build failed: %v", err)
+			if err := broker.Publish("error", server.BuildErrorEvent{Error: err.Error()}); err != nil {
+				logger.Printf("Failed to send event: %v", err)
+			}
+			return
+		}
+
+		current := server.PostSignatures(result.Posts)
+		event := server.DiffSignatures(signatures, current)
+		signatures = current
+		logger.Printf("Rebuilt: %d changed, %d removed", len(event.Changed), len(event.Removed))
+		if err := broker.Publish("rebuild", event); err != nil {
+			logger.Printf("Failed to send event: %v", err)
+		}
+	}
+	rebuild()
+
+	publicDir := filepath.Join(deploy.PublishedDir(cfg), "public_html")
+	router := server.NewRouter(cfg, os.DirFS(publicDir))
+	httpServer := &http.Server{
+		Addr: *addr,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/api/events" {
+				broker.ServeHTTP(w, r)
+				return
+			}
+			router.ServeHTTP(w, r)
+		}),
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	go func() {
+		logger.Printf("Serving %s on %s, live-reload events at /api/events", publicDir, *addr)
+		if err := server.ListenAndServe(ctx, httpServer); err != nil {
+			logger.Printf("Failed to serve: %v", err)
+			stop()
+		}
+	}()
+
+	watcher := server.NewContentWatcher(cfg, *poll, *pollInterval, *debounce)
+	if err := watcher.Watch(ctx, rebuild); err != nil {
+		logger.Fatalf("Failed to watch content: %v", err)
+	}
+}
+
+func configCommand(logger *log.Logger, args []string) {
+	if len(args) == 0 || args[0] != "print" {
+		logger.Fatalf("Usage: mantle config print [flags]")
+	}
+
+	flags := flag.NewFlagSet("config print", flag.ExitOnError)
+	configOpts := registerConfigFlags(flags)
+	buildOpts := registerBuildFlags(flags)
+	_ = flags.Parse(args[1:])
+
+	cfg, err := configOpts.load(buildOpts)
+	if cfg == nil {
+		logger.Fatalf("Failed to load config: %v", err)
+	}
+
+	if file := cfg.File(); file != "" {
+		fmt.Printf("# Config file: %s\n", file)
+	} else {
+		fmt.Println("# Config file: none")
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tENV\tVALUE\tSOURCE")
+	for _, setting := range cfg.Settings() {
+		fmt.Fprintf(w, "%s\t%s\t%q\t%s\n", setting.Key, setting.Env, setting.Value, setting.Source)
+	}
+	_ = w.Flush()
+
+	if err != nil {
+		logger.Fatalf("Configuration is not valid: %v", err)
+	}
+}
+
+func lintCommand(logger *log.Logger, args []string) {
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	format := flags.String("format", lint.FormatText, "Output format: text, json or sarif")
+	outputPath := flags.String("output", "", "Write the findings to this file instead of standard output")
+	failOn := flags.String("fail-on", string(lint.SeverityError), "Exit with a non-zero status on findings of this severity or worse: error, warning, note or never")
+	options := lint.DefaultOptions()
+	flags.IntVar(&options.MaxExcerptLength, "max-excerpt-length", options.MaxExcerptLength, "Longest excerpt, in characters, before it is reported (0 disables the check)")
+	configOpts := registerConfigFlags(flags)
+	_ = flags.Parse(args)
+
+	threshold, ok := severityRank[lint.Severity(*failOn)]
+	if !ok {
+		logger.Fatalf("Unknown --fail-on value %q, expected error, warning, note or never", *failOn)
+	}
+
+	cfg, err := configOpts.load(nil)
+	if err != nil {
+		logger.Fatalf("Failed to load config: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	findings, err := mantle.Lint(ctx, cfg, options)
+	if err != nil {
+		logger.Fatalf("Lint failed: %v", err)
+	}
+
+	out := os.Stdout
+	if *outputPath != "" {
+		file, err := os.Create(*outputPath)
+		if err != nil {
+			logger.Fatalf("Failed to create %s: %v", *outputPath, err)
+		}
+		defer file.Close()
+		out = file
+	}
+	if err := lint.Write(out, *format, findings, filepath.ToSlash(cfg.ContentDir)); err != nil {
+		logger.Fatalf("Failed to write findings: %v", err)
+	}
+
+	for _, finding := range findings {
+		if severityRank[finding.Severity] >= threshold {
+			if out != os.Stdout {
+				out.Close()
+			}
+			os.Exit(1)
+		}
+	}
+}
+
+// severityRank orders lint severities for --fail-on; "never" ranks above
+// every severity so that no finding fails the run.
+var severityRank = map[lint.Severity]int{
+	lint.SeverityNote:    1,
+	lint.SeverityWarning: 2,
+	lint.SeverityError:   3,
+	"never":              4,
+}
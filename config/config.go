@@ -0,0 +1,256 @@
+// Package config loads and validates Mantle settings from a config file and
+// the environment.
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	envconfig "github.com/Tech-Arch1tect/config"
+)
+
+const (
+	ContentFormatMarkdown = "markdown"
+	ContentFormatHTML     = "html"
+	ContentFormatBoth     = "both"
+)
+
+const (
+	FeedContentExcerpt = "excerpt"
+	FeedContentFull    = "full"
+)
+
+const (
+	ImageFormatWebP = "webp"
+	ImageFormatJPEG = "jpeg"
+)
+
+type Config struct {
+	ContentDir            string `env:"CONTENT_DIR" validate:"required"`
+	OutputDir             string `env:"OUTPUT_DIR" validate:"required"`
+	PostsPerPage          int    `env:"POSTS_PER_PAGE"`
+	PreviewsPerPage       int    `env:"PREVIEWS_PER_PAGE"`
+	DateFormat            string `env:"DATE_FORMAT"`
+	CorsAllowOrigin       string `env:"CORS_ALLOW_ORIGIN"`
+	CorsAllowMethods      string `env:"CORS_ALLOW_METHODS"`
+	CorsAllowHeaders      string `env:"CORS_ALLOW_HEADERS"`
+	CorsMaxAge            int    `env:"CORS_MAX_AGE"`
+	AverageWordsPerMinute int    `env:"AVERAGE_WORDS_PER_MINUTE"`
+	GenerateSwagger       bool   `env:"GENERATE_SWAGGER"`
+	SiteName              string `env:"SITE_NAME"`
+	SiteDescription       string `env:"SITE_DESCRIPTION"`
+	SiteTagline           string `env:"SITE_TAGLINE"`
+	ContentInclude        string `env:"CONTENT_INCLUDE"`
+	ContentExclude        string `env:"CONTENT_EXCLUDE"`
+	CategoryFromPath      bool   `env:"CATEGORY_FROM_PATH"`
+	BuildDrafts           bool   `env:"BUILD_DRAFTS"`
+	BuildFuture           bool   `env:"BUILD_FUTURE"`
+	BuildTime             string `env:"BUILD_TIME"`
+	ContentFormat         string `env:"CONTENT_FORMAT"`
+	HighlightCode         bool   `env:"HIGHLIGHT_CODE"`
+	HighlightStyle        string `env:"HIGHLIGHT_STYLE"`
+	TocMinDepth           int    `env:"TOC_MIN_DEPTH"`
+	TocMaxDepth           int    `env:"TOC_MAX_DEPTH"`
+	SiteURL               string `env:"SITE_URL"`
+	FeedLimit             int    `env:"FEED_LIMIT"`
+	FeedContent           string `env:"FEED_CONTENT"`
+	Permalink             string `env:"PERMALINK"`
+	BuildCache            bool   `env:"BUILD_CACHE"`
+	AtomicOutput          bool   `env:"ATOMIC_OUTPUT"`
+	KeepBuilds            int    `env:"KEEP_BUILDS"`
+	Strict                bool   `env:"STRICT"`
+	ProcessImages         bool   `env:"PROCESS_IMAGES"`
+	ImageWidths           string `env:"IMAGE_WIDTHS"`
+	ImageFormats          string `env:"IMAGE_FORMATS"`
+	ImageQuality          int    `env:"IMAGE_QUALITY"`
+	TaxonomyNavigation    bool   `env:"TAXONOMY_NAVIGATION"`
+
+	file        string
+	sources     map[string]Source
+	envProblems []Problem
+}
+
+func NewConfig() *Config {
+	return &Config{
+		ContentDir:            "./content",
+		OutputDir:             "./output",
+		PostsPerPage:          10,
+		PreviewsPerPage:       10,
+		DateFormat:            "2006-01-02",
+		CorsAllowOrigin:       "*",
+		CorsAllowMethods:      "GET, OPTIONS",
+		CorsAllowHeaders:      "Origin, X-Requested-With, Content-Type, Accept",
+		CorsMaxAge:            86400,
+		AverageWordsPerMinute: 200,
+		GenerateSwagger:       true,
+		ContentInclude:        "**/*.md",
+		ContentFormat:         ContentFormatMarkdown,
+		HighlightCode:         true,
+		HighlightStyle:        "github",
+		TocMinDepth:           2,
+		TocMaxDepth:           4,
+		SiteURL:               "http://localhost:8080",
+		FeedLimit:             20,
+		FeedContent:           FeedContentExcerpt,
+		Permalink:             "/{slug}",
+		BuildCache:            true,
+		KeepBuilds:            3,
+		ImageWidths:           "480,960,1440",
+		ImageFormats:          "jpeg,webp",
+		ImageQuality:          80,
+	}
+}
+
+// Load reads the config file discovered in the working directory, if any,
+// then the environment, and validates the result.
+func (c *Config) Load() error {
+	return c.LoadFrom("")
+}
+
+// LoadFrom is like Load but reads the config file at path instead of looking
+// for one. Environment variables take precedence over the file.
+func (c *Config) LoadFrom(path string) error {
+	c.SetDefaults()
+
+	if path == "" {
+		path = DiscoverFile(".")
+	}
+	if path != "" {
+		if err := c.LoadFile(path); err != nil {
+			return err
+		}
+	}
+
+	c.loadEnv()
+	if err := envconfig.ValidateStruct(c); err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	return c.Validate()
+}
+
+func (c *Config) SetDefaults() {
+	if c.ContentDir == "" {
+		c.ContentDir = "./content"
+	}
+	if c.OutputDir == "" {
+		c.OutputDir = "./output"
+	}
+	if c.PostsPerPage == 0 {
+		c.PostsPerPage = 10
+	}
+	if c.PreviewsPerPage == 0 {
+		c.PreviewsPerPage = 10
+	}
+	if c.DateFormat == "" {
+		c.DateFormat = "2006-01-02"
+	}
+	if c.CorsAllowOrigin == "" {
+		c.CorsAllowOrigin = "*"
+	}
+	if c.CorsAllowMethods == "" {
+		c.CorsAllowMethods = "GET, OPTIONS"
+	}
+	if c.CorsAllowHeaders == "" {
+		c.CorsAllowHeaders = "Origin, X-Requested-With, Content-Type, Accept"
+	}
+	if c.CorsMaxAge == 0 {
+		c.CorsMaxAge = 86400
+	}
+	if c.AverageWordsPerMinute == 0 {
+		c.AverageWordsPerMinute = 200
+	}
+	if c.SiteName == "" {
+		c.SiteName = "My Site"
+	}
+	if c.SiteDescription == "" {
+		c.SiteDescription = "My Site Description"
+	}
+	if c.SiteTagline == "" {
+		c.SiteTagline = "My Site Tagline"
+	}
+	if c.ContentInclude == "" {
+		c.ContentInclude = "**/*.md"
+	}
+	if c.ContentFormat == "" {
+		c.ContentFormat = ContentFormatMarkdown
+	}
+	if c.HighlightStyle == "" {
+		c.HighlightStyle = "github"
+	}
+	if c.TocMinDepth == 0 {
+		c.TocMinDepth = 2
+	}
+	if c.TocMaxDepth == 0 {
+		c.TocMaxDepth = 4
+	}
+	if c.SiteURL == "" {
+		c.SiteURL = "http://localhost:8080"
+	}
+	if c.FeedLimit == 0 {
+		c.FeedLimit = 20
+	}
+	if c.FeedContent == "" {
+		c.FeedContent = FeedContentExcerpt
+	}
+	if c.Permalink == "" {
+		c.Permalink = "/{slug}"
+	}
+	if c.ImageWidths == "" {
+		c.ImageWidths = "480,960,1440"
+	}
+	if c.ImageFormats == "" {
+		c.ImageFormats = "jpeg,webp"
+	}
+	if c.ImageQuality == 0 {
+		c.ImageQuality = 80
+	}
+}
+
+func (c *Config) String() string {
+	return fmt.Sprintf("Config{ContentDir: %q, OutputDir: %q, PostsPerPage: %d, PreviewsPerPage: %d, DateFormat: %q, CorsAllowOrigin: %q, CorsAllowMethods: %q, CorsAllowHeaders: %q, CorsMaxAge: %d, GenerateSwagger: %t, ContentInclude: %q, ContentExclude: %q, CategoryFromPath: %t, BuildDrafts: %t, BuildFuture: %t, BuildTime: %q, ContentFormat: %q, HighlightCode: %t, HighlightStyle: %q, TocMinDepth: %d, TocMaxDepth: %d, SiteURL: %q, FeedLimit: %d, FeedContent: %q, Permalink: %q, BuildCache: %t, AtomicOutput: %t, KeepBuilds: %d, Strict: %t, ProcessImages: %t, ImageWidths: %q, ImageFormats: %q, ImageQuality: %d, TaxonomyNavigation: %t}",
+		c.ContentDir, c.OutputDir, c.PostsPerPage, c.PreviewsPerPage, c.DateFormat, c.CorsAllowOrigin, c.CorsAllowMethods, c.CorsAllowHeaders, c.CorsMaxAge, c.GenerateSwagger, c.ContentInclude, c.ContentExclude, c.CategoryFromPath, c.BuildDrafts, c.BuildFuture, c.BuildTime, c.ContentFormat, c.HighlightCode, c.HighlightStyle, c.TocMinDepth, c.TocMaxDepth, c.SiteURL, c.FeedLimit, c.FeedContent, c.Permalink, c.BuildCache, c.AtomicOutput, c.KeepBuilds, c.Strict, c.ProcessImages, c.ImageWidths, c.ImageFormats, c.ImageQuality, c.TaxonomyNavigation)
+}
+
+func (c *Config) RendersHTML() bool {
+	return c.ContentFormat != ContentFormatMarkdown || c.FeedContent == FeedContentFull
+}
+
+func (c *Config) BuildClock() (time.Time, error) {
+	if c.BuildTime == "" {
+		return time.Now(), nil
+	}
+
+	for _, layout := range []string{time.RFC3339, c.DateFormat} {
+		if buildTime, err := time.Parse(layout, c.BuildTime); err == nil {
+			return buildTime, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("build time %q must be RFC 3339 or match date format %q", c.BuildTime, c.DateFormat)
+}
+
+// ImageWidthList returns the widths from IMAGE_WIDTHS, skipping any that are
+// not positive integers.
+func (c *Config) ImageWidthList() []int {
+	var widths []int
+	for _, item := range SplitList(c.ImageWidths) {
+		if width, err := strconv.Atoi(item); err == nil && width > 0 {
+			widths = append(widths, width)
+		}
+	}
+	return widths
+}
+
+// SplitList splits a comma-separated setting into its non-empty items.
+func SplitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeConfigFile(t *testing.T, contents string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "content"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "mantle.yaml")
+	contents = "content_dir: " + filepath.Join(dir, "content") + "\noutput_dir: " + filepath.Join(dir, "output") + "\n" + contents
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFromKeepsFileValues(t *testing.T) {
+	path := writeConfigFile(t, "cors_max_age: 0\nbuild_cache: false\nposts_per_page: 25\n")
+
+	cfg := NewConfig()
+	if err := cfg.LoadFrom(path); err != nil {
+		t.Fatalf("LoadFrom: %v", err)
+	}
+
+	if cfg.CorsMaxAge != 0 {
+		t.Errorf("CorsMaxAge = %d, want 0 from the file", cfg.CorsMaxAge)
+	}
+	if cfg.BuildCache {
+		t.Errorf("BuildCache = true, want false from the file")
+	}
+	if cfg.PostsPerPage != 25 {
+		t.Errorf("PostsPerPage = %d, want 25", cfg.PostsPerPage)
+	}
+	if source := cfg.Source("cors_max_age"); source != SourceFile {
+		t.Errorf("cors_max_age source = %s, want %s", source, SourceFile)
+	}
+}
+
+func TestLoadFromReportsUnparsableEnv(t *testing.T) {
+	path := writeConfigFile(t, "posts_per_page: 25\n")
+
+	tests := []struct {
+		env   string
+		value string
+	}{
+		{"POSTS_PER_PAGE", "abc"},
+		{"BUILD_CACHE", "yes"},
+		{"CORS_MAX_AGE", "1h"},
+	}
+	for _, tt := range tests {
+		t.Setenv(tt.env, tt.value)
+	}
+
+	cfg := NewConfig()
+	err := cfg.LoadFrom(path)
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("LoadFrom error = %v, want a *ValidationError", err)
+	}
+	for _, tt := range tests {
+		found := false
+		for _, problem := range validationErr.Problems {
+			if problem.Field == tt.env && problem.Value == tt.value && problem.Source == SourceEnv && problem.Fix != "" {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("no problem reported for %s=%q in %v", tt.env, tt.value, validationErr.Problems)
+		}
+	}
+
+	if cfg.PostsPerPage != 25 {
+		t.Errorf("PostsPerPage = %d, want the file value 25 to be kept", cfg.PostsPerPage)
+	}
+}
+
+func TestLoadFromAppliesEnv(t *testing.T) {
+	path := writeConfigFile(t, "posts_per_page: 25\n")
+	t.Setenv("POSTS_PER_PAGE", "30")
+	t.Setenv("SITE_NAME", "")
+
+	cfg := NewConfig()
+	if err := cfg.LoadFrom(path); err != nil {
+		t.Fatalf("LoadFrom: %v", err)
+	}
+
+	if cfg.PostsPerPage != 30 {
+		t.Errorf("PostsPerPage = %d, want 30 from the environment", cfg.PostsPerPage)
+	}
+	if source := cfg.Source("posts_per_page"); source != SourceEnv {
+		t.Errorf("posts_per_page source = %s, want %s", source, SourceEnv)
+	}
+	if cfg.SiteName != "My Site" {
+		t.Errorf("SiteName = %q, want the default when the variable is empty", cfg.SiteName)
+	}
+}
+
+func TestValidateSettingsSkipsDirs(t *testing.T) {
+	cfg := NewConfig()
+	cfg.ContentDir = filepath.Join(t.TempDir(), "missing")
+	cfg.PostsPerPage = 0
+
+	var validationErr *ValidationError
+	if err := cfg.Validate(); !errors.As(err, &validationErr) || !hasProblem(validationErr, "CONTENT_DIR") {
+		t.Errorf("Validate = %v, want a CONTENT_DIR problem", err)
+	}
+	err := cfg.ValidateSettings()
+	if !errors.As(err, &validationErr) || hasProblem(validationErr, "CONTENT_DIR") || !hasProblem(validationErr, "POSTS_PER_PAGE") {
+		t.Errorf("ValidateSettings = %v, want only the POSTS_PER_PAGE problem", err)
+	}
+}
+
+func hasProblem(err *ValidationError, field string) bool {
+	for _, problem := range err.Problems {
+		if problem.Field == field {
+			return true
+		}
+	}
+	return false
+}
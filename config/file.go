@@ -0,0 +1,244 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// Source records where the effective value of a setting came from.
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+// DefaultFiles are the config file names looked for in the working directory,
+// in order, when no file is given explicitly.
+var DefaultFiles = []string{"mantle.yaml", "mantle.yml", "mantle.toml"}
+
+// Setting describes the effective value of one configuration field.
+type Setting struct {
+	Key    string `json:"key"`
+	Env    string `json:"env"`
+	Value  string `json:"value"`
+	Source Source `json:"source"`
+}
+
+// DiscoverFile returns the first of DefaultFiles present in dir, or an empty
+// string when there is none.
+func DiscoverFile(dir string) string {
+	for _, name := range DefaultFiles {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// File returns the path of the config file that was loaded, if any.
+func (c *Config) File() string {
+	return c.file
+}
+
+// Source reports where the value of the setting with the given key came from.
+func (c *Config) Source(key string) Source {
+	if source, ok := c.sources[key]; ok {
+		return source
+	}
+	return SourceDefault
+}
+
+// Settings lists every setting with its effective value and source, in
+// declaration order.
+func (c *Config) Settings() []Setting {
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+
+	var settings []Setting
+	for i := 0; i < t.NumField(); i++ {
+		env := t.Field(i).Tag.Get("env")
+		if env == "" {
+			continue
+		}
+		key := strings.ToLower(env)
+		settings = append(settings, Setting{
+			Key:    key,
+			Env:    env,
+			Value:  fmt.Sprint(v.Field(i).Interface()),
+			Source: c.Source(key),
+		})
+	}
+	return settings
+}
+
+// Set assigns value to the setting with the given key, the lower-case name of
+// its environment variable (e.g. "content_dir"), and records its source.
+func (c *Config) Set(key, value string, source Source) error {
+	field, ok := c.field(key)
+	if !ok {
+		return fmt.Errorf("unknown setting %q", key)
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("setting %s must be a whole number, got %q", key, value)
+		}
+		field.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("setting %s must be true or false, got %q", key, value)
+		}
+		field.SetBool(b)
+	default:
+		return fmt.Errorf("setting %s has unsupported type %s", key, field.Kind())
+	}
+
+	c.setSource(key, source)
+	return nil
+}
+
+// LoadFile applies the settings in a YAML (.yaml, .yml) or TOML (.toml)
+// config file. Keys are the lower-case environment variable names.
+func (c *Config) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	values := make(map[string]interface{})
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	default:
+		return fmt.Errorf("config file %s must have a .yaml, .yml or .toml extension", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value, err := fileValue(values[key])
+		if err != nil {
+			return fmt.Errorf("invalid value for %s in %s: %w", key, path, err)
+		}
+		if err := c.Set(key, value, SourceFile); err != nil {
+			return fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+	}
+
+	c.file = path
+	return nil
+}
+
+// loadEnv applies the settings set in the environment on top of the file.
+// Empty values are ignored. A value that cannot be parsed for its setting
+// leaves the setting unchanged and is kept as a problem for Validate.
+func (c *Config) loadEnv() {
+	c.envProblems = nil
+
+	t := reflect.TypeOf(c).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		env := t.Field(i).Tag.Get("env")
+		if env == "" {
+			continue
+		}
+		value, ok := os.LookupEnv(env)
+		if !ok || value == "" {
+			continue
+		}
+		if err := c.Set(strings.ToLower(env), value, SourceEnv); err == nil {
+			continue
+		}
+
+		problem := Problem{Field: env, Value: value, Source: SourceEnv}
+		switch t.Field(i).Type.Kind() {
+		case reflect.Int:
+			problem.Message = "is not a whole number"
+			problem.Fix = fmt.Sprintf("set it to a number without units, e.g. %s=%d", env, reflect.ValueOf(c).Elem().Field(i).Int())
+		case reflect.Bool:
+			problem.Message = "is not true or false"
+			problem.Fix = "set it to true or false"
+		default:
+			problem.Message = "cannot be parsed"
+		}
+		c.envProblems = append(c.envProblems, problem)
+	}
+}
+
+func (c *Config) setSource(key string, source Source) {
+	if c.sources == nil {
+		c.sources = make(map[string]Source)
+	}
+	c.sources[key] = source
+}
+
+func (c *Config) field(key string) (reflect.Value, bool) {
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if env := t.Field(i).Tag.Get("env"); env != "" && strings.ToLower(env) == key {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// fileValue converts a decoded YAML or TOML value to the string form accepted
+// by Set. Lists are joined with commas for the comma-separated settings.
+func fileValue(raw interface{}) (string, error) {
+	switch value := raw.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return value, nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	case int:
+		return strconv.Itoa(value), nil
+	case int64:
+		return strconv.FormatInt(value, 10), nil
+	case float64:
+		if value != math.Trunc(value) {
+			return "", fmt.Errorf("expected a whole number, got %v", value)
+		}
+		return strconv.FormatInt(int64(value), 10), nil
+	case []interface{}:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			s, err := fileValue(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, s)
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return "", fmt.Errorf("unsupported value of type %T", raw)
+	}
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PermalinkPlaceholders are the placeholders supported in PERMALINK.
+var PermalinkPlaceholders = []string{"{year}", "{month}", "{day}", "{slug}", "{category}"}
+
+func validatePermalink(template string) error {
+	if !strings.HasPrefix(template, "/") {
+		return fmt.Errorf("permalink %q must start with /", template)
+	}
+	if !strings.Contains(template, "{slug}") {
+		return fmt.Errorf("permalink %q must contain {slug} so that every post has a unique URL", template)
+	}
+
+	remaining := template
+	for _, placeholder := range PermalinkPlaceholders {
+		remaining = strings.ReplaceAll(remaining, placeholder, "")
+	}
+	if strings.ContainsAny(remaining, "{}") {
+		return fmt.Errorf("permalink %q contains an unknown placeholder; supported placeholders are %s",
+			template, strings.Join(PermalinkPlaceholders, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,347 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/tech-arch1tect/mantle/internal/glob"
+)
+
+// Problem describes one invalid setting and how to fix it.
+type Problem struct {
+	Field   string `json:"field"`
+	Value   string `json:"value"`
+	Source  Source `json:"source"`
+	Message string `json:"message"`
+	Fix     string `json:"fix,omitempty"`
+}
+
+func (p Problem) String() string {
+	s := fmt.Sprintf("%s=%q (%s): %s", p.Field, p.Value, p.Source, p.Message)
+	if p.Fix != "" {
+		s += "\n    fix: " + p.Fix
+	}
+	return s
+}
+
+// ValidationError lists every problem found by Validate.
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "invalid configuration, %d problem(s):", len(e.Problems))
+	for _, problem := range e.Problems {
+		b.WriteString("\n  ")
+		b.WriteString(problem.String())
+	}
+	return b.String()
+}
+
+// Validate checks that the settings are usable together. All problems are
+// reported at once as a *ValidationError.
+func (c *Config) Validate() error {
+	return c.validate(true)
+}
+
+// ValidateSettings is Validate without the checks on CONTENT_DIR and
+// OUTPUT_DIR, for commands that never read content.
+func (c *Config) ValidateSettings() error {
+	return c.validate(false)
+}
+
+func (c *Config) validate(dirs bool) error {
+	v := &validator{config: c}
+
+	// Environment values that could not be parsed were not applied, unless a
+	// flag has replaced them since.
+	for _, problem := range c.envProblems {
+		if c.Source(strings.ToLower(problem.Field)) != SourceFlag {
+			v.problems = append(v.problems, problem)
+		}
+	}
+
+	v.atLeast("POSTS_PER_PAGE", c.PostsPerPage, 1)
+	v.atLeast("PREVIEWS_PER_PAGE", c.PreviewsPerPage, 1)
+	v.atLeast("AVERAGE_WORDS_PER_MINUTE", c.AverageWordsPerMinute, 1)
+	v.atLeast("FEED_LIMIT", c.FeedLimit, 1)
+	v.atLeast("CORS_MAX_AGE", c.CorsMaxAge, 0)
+	v.atLeast("KEEP_BUILDS", c.KeepBuilds, 0)
+
+	v.oneOf("CONTENT_FORMAT", c.ContentFormat, ContentFormatMarkdown, ContentFormatHTML, ContentFormatBoth)
+	v.oneOf("FEED_CONTENT", c.FeedContent, FeedContentExcerpt, FeedContentFull)
+
+	if dirs {
+		v.validateDirs()
+	}
+	v.validateDateFormat()
+	v.validateBuildTime()
+	v.validateToc()
+	v.validateSiteURL()
+	v.validateHighlightStyle()
+	v.validateCors()
+	v.validateImages()
+
+	if err := validatePermalink(c.Permalink); err != nil {
+		v.add("PERMALINK", c.Permalink, err.Error(),
+			fmt.Sprintf("use a path such as \"/{year}/{slug}\" built from %s", strings.Join(PermalinkPlaceholders, ", ")))
+	}
+
+	for _, field := range []string{"CONTENT_INCLUDE", "CONTENT_EXCLUDE"} {
+		value := c.ContentInclude
+		if field == "CONTENT_EXCLUDE" {
+			value = c.ContentExclude
+		}
+		for _, pattern := range SplitList(value) {
+			if err := glob.Validate(pattern); err != nil {
+				v.add(field, value, fmt.Sprintf("pattern %q is invalid: %v", pattern, err),
+					"check for unbalanced [ ] brackets or a trailing backslash")
+			}
+		}
+	}
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+type validator struct {
+	config   *Config
+	problems []Problem
+}
+
+func (v *validator) add(field, value, message, fix string) {
+	v.problems = append(v.problems, Problem{
+		Field:   field,
+		Value:   value,
+		Source:  v.config.Source(strings.ToLower(field)),
+		Message: message,
+		Fix:     fix,
+	})
+}
+
+func (v *validator) atLeast(field string, value, minimum int) {
+	if value < minimum {
+		v.add(field, fmt.Sprint(value), fmt.Sprintf("must be at least %d", minimum), fmt.Sprintf("set it to %d or more", minimum))
+	}
+}
+
+func (v *validator) oneOf(field, value string, options ...string) {
+	for _, option := range options {
+		if value == option {
+			return
+		}
+	}
+
+	fix := fmt.Sprintf("use one of %s", quoteList(options))
+	if suggestion := closest(value, options); suggestion != "" {
+		fix = fmt.Sprintf("did you mean %q?", suggestion)
+	}
+	v.add(field, value, "is not a recognised value", fix)
+}
+
+func (v *validator) validateDirs() {
+	c := v.config
+
+	info, err := os.Stat(c.ContentDir)
+	switch {
+	case err != nil && os.IsNotExist(err):
+		v.add("CONTENT_DIR", c.ContentDir, "does not exist", "create the directory or point CONTENT_DIR at your markdown files")
+	case err != nil:
+		v.add("CONTENT_DIR", c.ContentDir, fmt.Sprintf("cannot be read: %v", err), "check the directory permissions")
+	case !info.IsDir():
+		v.add("CONTENT_DIR", c.ContentDir, "is not a directory", "point CONTENT_DIR at the directory containing your markdown files")
+	}
+
+	contentDir, err := filepath.Abs(c.ContentDir)
+	if err != nil {
+		return
+	}
+	outputDir, err := filepath.Abs(c.OutputDir)
+	if err != nil {
+		return
+	}
+	switch {
+	case contentDir == outputDir:
+		v.add("OUTPUT_DIR", c.OutputDir, "is the same directory as CONTENT_DIR", "write the output to a separate directory such as ./output")
+	case strings.HasPrefix(outputDir, contentDir+string(filepath.Separator)):
+		v.add("OUTPUT_DIR", c.OutputDir, "is inside CONTENT_DIR, so generated files would be read back as content",
+			"move OUTPUT_DIR outside CONTENT_DIR")
+	}
+}
+
+func (v *validator) validateDateFormat() {
+	layout := v.config.DateFormat
+	// Any date other than Go's reference date will do, as long as each of its
+	// fields differs from the layout elements.
+	sample := time.Date(2019, time.November, 23, 18, 47, 39, 0, time.UTC)
+	fix := "write the layout using Go's reference date, e.g. \"2006-01-02\" or \"02/01/2006\""
+
+	formatted := sample.Format(layout)
+	if formatted == layout {
+		v.add("DATE_FORMAT", layout, "contains no Go date layout elements", fix)
+		return
+	}
+
+	parsed, err := time.Parse(layout, formatted)
+	if err != nil || parsed.Year() != 2019 || parsed.Month() != time.November || parsed.Day() != 23 {
+		v.add("DATE_FORMAT", layout, "must include the year, month and day", fix)
+	}
+}
+
+func (v *validator) validateBuildTime() {
+	if _, err := v.config.BuildClock(); err != nil {
+		v.add("BUILD_TIME", v.config.BuildTime, "is not a valid time",
+			fmt.Sprintf("use RFC 3339 (e.g. \"2024-06-04T12:00:00Z\") or DATE_FORMAT (%q)", v.config.DateFormat))
+	}
+}
+
+func (v *validator) validateToc() {
+	c := v.config
+	if c.TocMinDepth < 1 || c.TocMinDepth > 6 {
+		v.add("TOC_MIN_DEPTH", fmt.Sprint(c.TocMinDepth), "must be a heading level between 1 and 6", "set it to 2 to skip the post title")
+	}
+	if c.TocMaxDepth < 1 || c.TocMaxDepth > 6 {
+		v.add("TOC_MAX_DEPTH", fmt.Sprint(c.TocMaxDepth), "must be a heading level between 1 and 6", "set it to 4 or lower")
+	}
+	if c.TocMinDepth > c.TocMaxDepth {
+		v.add("TOC_MIN_DEPTH", fmt.Sprint(c.TocMinDepth), fmt.Sprintf("is deeper than TOC_MAX_DEPTH (%d)", c.TocMaxDepth),
+			"swap the two values")
+	}
+}
+
+func (v *validator) validateSiteURL() {
+	siteURL, err := url.Parse(v.config.SiteURL)
+	if err != nil || siteURL.Scheme == "" || siteURL.Host == "" {
+		v.add("SITE_URL", v.config.SiteURL, "must be an absolute URL", "include the scheme and host, e.g. \"https://blog.example.com\"")
+		return
+	}
+	if siteURL.Scheme != "http" && siteURL.Scheme != "https" {
+		v.add("SITE_URL", v.config.SiteURL, fmt.Sprintf("uses unsupported scheme %q", siteURL.Scheme), "use http or https")
+	}
+}
+
+func (v *validator) validateHighlightStyle() {
+	style := v.config.HighlightStyle
+	if _, ok := styles.Registry[style]; ok {
+		return
+	}
+
+	fix := "use a chroma style such as \"github\", \"monokai\" or \"dracula\""
+	if suggestion := closest(style, styles.Names()); suggestion != "" {
+		fix = fmt.Sprintf("did you mean %q?", suggestion)
+	}
+	v.add("HIGHLIGHT_STYLE", style, "is not a known chroma style", fix)
+}
+
+// validateCors rejects values that cannot be embedded in the double-quoted
+// add_header directives of the generated nginx configuration.
+func (v *validator) validateCors() {
+	c := v.config
+	for _, setting := range []struct{ field, value string }{
+		{"CORS_ALLOW_ORIGIN", c.CorsAllowOrigin},
+		{"CORS_ALLOW_METHODS", c.CorsAllowMethods},
+		{"CORS_ALLOW_HEADERS", c.CorsAllowHeaders},
+	} {
+		if i := strings.IndexFunc(setting.value, unsafeHeaderRune); i >= 0 {
+			v.add(setting.field, setting.value,
+				fmt.Sprintf("contains %q, which would break the generated nginx configuration", string(setting.value[i])),
+				"remove quotes, backslashes, $ signs and line breaks")
+		}
+	}
+
+	if origin := c.CorsAllowOrigin; origin != "*" && origin != "null" && !strings.ContainsAny(origin, "\"\\$") {
+		if u, err := url.Parse(origin); err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			v.add("CORS_ALLOW_ORIGIN", origin, "must be * or a single origin", "use a scheme and host without a path, e.g. \"https://example.com\"")
+		}
+	}
+}
+
+func (v *validator) validateImages() {
+	c := v.config
+	if c.ImageQuality < 1 || c.ImageQuality > 100 {
+		v.add("IMAGE_QUALITY", fmt.Sprint(c.ImageQuality), "must be between 1 and 100", "set it to 80")
+	}
+
+	for _, item := range SplitList(c.ImageWidths) {
+		if width, err := strconv.Atoi(item); err != nil || width < 1 {
+			v.add("IMAGE_WIDTHS", c.ImageWidths, fmt.Sprintf("width %q is not a positive number of pixels", item),
+				"use a comma-separated list such as \"480,960,1440\"")
+		}
+	}
+
+	formats := []string{ImageFormatWebP, ImageFormatJPEG}
+	for _, format := range SplitList(c.ImageFormats) {
+		found := false
+		for _, option := range formats {
+			found = found || format == option
+		}
+		if found {
+			continue
+		}
+		fix := fmt.Sprintf("use a comma-separated list of %s", quoteList(formats))
+		if suggestion := closest(format, formats); suggestion != "" {
+			fix = fmt.Sprintf("did you mean %q?", suggestion)
+		}
+		v.add("IMAGE_FORMATS", c.ImageFormats, fmt.Sprintf("format %q is not supported", format), fix)
+	}
+
+}
+
+func unsafeHeaderRune(r rune) bool {
+	return r == '"' || r == '\\' || r == '$' || r < ' ' || r == 0x7f
+}
+
+// closest returns the option most similar to value, or an empty string when
+// none is close enough to be a likely typo.
+func closest(value string, options []string) string {
+	best, bestDistance := "", len(value)/3+2
+	sorted := append([]string(nil), options...)
+	sort.Strings(sorted)
+	for _, option := range sorted {
+		if strings.EqualFold(option, value) {
+			return option
+		}
+		if d := levenshtein(strings.ToLower(value), strings.ToLower(option)); d < bestDistance {
+			best, bestDistance = option, d
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func quoteList(options []string) string {
+	quoted := make([]string, len(options))
+	for i, option := range options {
+		quoted[i] = fmt.Sprintf("%q", option)
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,170 @@
+package content
+
+import (
+	"io/fs"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/tech-arch1tect/mantle/internal/glob"
+)
+
+// BundleIndex is the file name that turns a directory into a page bundle: the
+// post is read from it and every other file in the directory is published
+// alongside the post as an Asset.
+const BundleIndex = "index.md"
+
+var (
+	// inlineDestinationPattern matches the destination of an inline link or
+	// image, such as the "shot.png" in ![Screenshot](shot.png "Title") or
+	// the "my shot.png" in ![Screenshot](<my shot.png>).
+	inlineDestinationPattern = regexp.MustCompile(`\]\(\s*(?:<([^<>\n]+)>|([^()<>\s]+))`)
+	// referenceDestinationPattern matches the destination of a link
+	// reference definition, such as [shot]: images/shot.png.
+	referenceDestinationPattern = regexp.MustCompile(`^\s{0,3}\[[^\]]+\]:\s*<?([^<>\s]+)>?`)
+	// htmlDestinationPattern matches src and href attributes of raw HTML.
+	htmlDestinationPattern = regexp.MustCompile(`\b(?:src|href)\s*=\s*["']([^"']+)["']`)
+)
+
+// @Description File published from a post's page bundle
+type Asset struct {
+	Name       string `json:"name" example:"images/screenshot.png"`
+	URL        string `json:"url" example:"/media/getting-started-with-go/images/screenshot.png"`
+	Size       int64  `json:"size" example:"48213"`
+	Image      *Image `json:"image,omitempty"`
+	SourcePath string `json:"-"`
+}
+
+// IsBundle reports whether the post at file, relative to CONTENT_DIR, is the
+// index of a page bundle.
+func IsBundle(file string) bool {
+	return path.Base(file) == BundleIndex && path.Dir(file) != "."
+}
+
+// MediaURL returns the published URL of an asset of the post with the given
+// slug.
+func MediaURL(slug, name string) string {
+	return (&url.URL{Path: path.Join("/media", slug, name)}).EscapedPath()
+}
+
+// bundleAssets lists the files in the bundle directory of file, skipping
+// markdown, hidden and excluded files and nested bundles.
+func (pl *PostLoader) bundleAssets(file, slug string) ([]Asset, error) {
+	dir := path.Dir(file)
+
+	var assets []Asset
+	err := fs.WalkDir(pl.fs, dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == dir {
+			return nil
+		}
+		if strings.HasPrefix(entry.Name(), ".") || glob.MatchAny(pl.exclude, p) {
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if entry.IsDir() {
+			if pl.fileExists(path.Join(p, BundleIndex)) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(entry.Name(), ".md") {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		name := strings.TrimPrefix(p, dir+"/")
+		assets = append(assets, Asset{
+			Name:       name,
+			URL:        MediaURL(slug, name),
+			Size:       info.Size(),
+			SourcePath: p,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return assets, nil
+}
+
+// rewriteAssetReferences points relative links, images and HTML attributes in
+// source that refer to one of the assets of post at the published copy.
+func rewriteAssetReferences(post *Post, source string) string {
+	if len(post.Assets) == 0 {
+		return source
+	}
+
+	urls := make(map[string]string, len(post.Assets))
+	for _, asset := range post.Assets {
+		urls[asset.SourcePath] = asset.URL
+	}
+
+	dir := path.Dir(post.SourcePath)
+	rewrite := func(destination string) string {
+		file, u, ok := relativeFile(dir, destination)
+		if !ok {
+			return destination
+		}
+		published, ok := urls[file]
+		if !ok {
+			return destination
+		}
+		u.Path, u.RawPath = "", ""
+		return published + u.String()
+	}
+
+	return rewriteOutsideCode(source, func(text string, _ int) string {
+		return replaceDestinations(text, rewrite)
+	})
+}
+
+// replaceDestinations replaces the destinations of links, images, reference
+// definitions and HTML src and href attributes in text.
+func replaceDestinations(text string, replace func(string) string) string {
+	for _, pattern := range []*regexp.Regexp{inlineDestinationPattern, referenceDestinationPattern, htmlDestinationPattern} {
+		text = replaceSubmatch(pattern, text, replace)
+	}
+	return text
+}
+
+// relativeFile resolves a destination relative to dir, such as images/a.png,
+// to a path relative to CONTENT_DIR. It reports false for URLs and absolute
+// paths.
+func relativeFile(dir, destination string) (string, *url.URL, bool) {
+	u, err := url.Parse(destination)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" || strings.HasPrefix(u.Path, "/") {
+		return "", nil, false
+	}
+	return path.Clean(path.Join(dir, u.Path)), u, true
+}
+
+// replaceSubmatch replaces the first capture group that took part in each
+// match of pattern in text.
+func replaceSubmatch(pattern *regexp.Regexp, text string, replace func(string) string) string {
+	var b strings.Builder
+	last := 0
+	for _, match := range pattern.FindAllStringSubmatchIndex(text, -1) {
+		group := 2
+		for group < len(match)-2 && match[group] < 0 {
+			group += 2
+		}
+		if match[group] < 0 {
+			continue
+		}
+		b.WriteString(text[last:match[group]])
+		b.WriteString(replace(text[match[group]:match[group+1]]))
+		last = match[group+1]
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
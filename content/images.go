@@ -0,0 +1,277 @@
+package content
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/tech-arch1tect/mantle/cache"
+	"github.com/tech-arch1tect/mantle/config"
+	"github.com/tech-arch1tect/mantle/internal/imaging"
+)
+
+// @Description Image dimensions, resized variants and a placeholder to show while it loads
+type Image struct {
+	URL         string         `json:"url" example:"/media/getting-started-with-go/images/screenshot.png"`
+	Width       int            `json:"width,omitempty" example:"1920"`
+	Height      int            `json:"height,omitempty" example:"1080"`
+	Placeholder string         `json:"placeholder,omitempty" example:"data:image/jpeg;base64,/9j/2wBDAAoHBwg..."`
+	Variants    []ImageVariant `json:"variants,omitempty"`
+}
+
+// @Description Resized copy of an image
+type ImageVariant struct {
+	URL    string `json:"url" example:"/media/getting-started-with-go/images/screenshot.png-960w.webp"`
+	Width  int    `json:"width" example:"960"`
+	Height int    `json:"height" example:"540"`
+	Format string `json:"format" example:"webp"`
+}
+
+// addCoverAsset publishes the coverImage of post with its assets when it is a
+// relative path to a file that is not already one of them.
+func (pl *PostLoader) addCoverAsset(post *Post) {
+	source, ok := coverSource(post)
+	if !ok {
+		return
+	}
+	for _, asset := range post.Assets {
+		if asset.SourcePath == source {
+			return
+		}
+	}
+
+	info, err := fs.Stat(pl.fs, source)
+	if err != nil || info.IsDir() {
+		pl.report(post.SourcePath, IssueBrokenLink, "coverImage %q points to %s, which does not exist",
+			post.FrontMatter.CoverImage, source)
+		return
+	}
+
+	// A cover from outside a bundle may share its name with a file in it.
+	name := uniqueAssetName(post.Assets, path.Base(source))
+	post.Assets = append(post.Assets, Asset{
+		Name:       name,
+		URL:        MediaURL(post.FrontMatter.Slug, name),
+		Size:       info.Size(),
+		SourcePath: source,
+	})
+}
+
+// uniqueAssetName returns name, or name with a number added before its
+// extension when one of assets already has it.
+func uniqueAssetName(assets []Asset, name string) string {
+	taken := make(map[string]bool, len(assets))
+	for _, asset := range assets {
+		taken[asset.Name] = true
+	}
+
+	ext := path.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	for n := 2; taken[name]; n++ {
+		name = fmt.Sprintf("%s-%d%s", stem, n, ext)
+	}
+	return name
+}
+
+// cover returns the cover image of post. Images given by URL are returned
+// as they are, without dimensions.
+func cover(post *Post) *Image {
+	if post.FrontMatter.CoverImage == "" {
+		return nil
+	}
+
+	source, ok := coverSource(post)
+	if !ok {
+		return &Image{URL: post.FrontMatter.CoverImage}
+	}
+	for _, asset := range post.Assets {
+		if asset.SourcePath != source {
+			continue
+		}
+		if asset.Image != nil {
+			image := *asset.Image
+			return &image
+		}
+		return &Image{URL: asset.URL}
+	}
+	return nil
+}
+
+// coverSource resolves a relative coverImage against the directory of post.
+func coverSource(post *Post) (string, bool) {
+	source, _, ok := relativeFile(path.Dir(post.SourcePath), post.FrontMatter.CoverImage)
+	return source, ok
+}
+
+// addReferencedImages publishes the images that the body of post refers to by
+// a relative path with its assets, so that images of posts outside bundles
+// are processed too, and points the references at the published copies.
+// References to images that do not exist are reported.
+func (pl *PostLoader) addReferencedImages(post *Post) {
+	known := make(map[string]bool, len(post.Assets))
+	for _, asset := range post.Assets {
+		known[asset.SourcePath] = true
+	}
+
+	dir := path.Dir(post.SourcePath)
+	rewriteOutsideCode(post.Markdown, func(text string, n int) string {
+		return replaceDestinations(text, func(destination string) string {
+			source, _, ok := relativeFile(dir, destination)
+			if !ok || !imaging.IsImage(source) || known[source] {
+				return destination
+			}
+			known[source] = true
+
+			info, err := fs.Stat(pl.fs, source)
+			if err != nil || info.IsDir() {
+				pl.reportLine(post.SourcePath, post.BodyOffset+n+1, IssueBrokenLink,
+					"image %q points to %s, which does not exist", destination, source)
+				return destination
+			}
+			name := uniqueAssetName(post.Assets, path.Base(source))
+			post.Assets = append(post.Assets, Asset{
+				Name:       name,
+				URL:        MediaURL(post.FrontMatter.Slug, name),
+				Size:       info.Size(),
+				SourcePath: source,
+			})
+			return destination
+		})
+	})
+
+	post.Markdown = rewriteAssetReferences(post, post.Markdown)
+	post.Excerpt = rewriteAssetReferences(post, post.Excerpt)
+}
+
+// describeImages reads the dimensions of every image asset of post and plans
+// its resized variants. Results are cached by the content of the image.
+func (pl *PostLoader) describeImages(post *Post) {
+	for i := range post.Assets {
+		asset := &post.Assets[i]
+		if !imaging.IsImage(asset.Name) {
+			continue
+		}
+
+		image, err := pl.describeImage(post.FrontMatter.Slug, *asset)
+		if err != nil {
+			pl.report(post.SourcePath, IssueInvalidImage, "%s: %v", asset.SourcePath, err)
+			continue
+		}
+		asset.Image = image
+	}
+
+	// A variant must not overwrite an asset that happens to have its name.
+	urls := make(map[string]bool, len(post.Assets))
+	for _, asset := range post.Assets {
+		urls[asset.URL] = true
+	}
+	for _, asset := range post.Assets {
+		if asset.Image == nil {
+			continue
+		}
+		variants := asset.Image.Variants[:0]
+		for _, variant := range asset.Image.Variants {
+			if urls[variant.URL] {
+				pl.report(post.SourcePath, IssueInvalidImage, "%s: variant %s is not generated because an asset has the same name",
+					asset.SourcePath, variant.URL)
+				continue
+			}
+			variants = append(variants, variant)
+		}
+		asset.Image.Variants = variants
+	}
+}
+
+func (pl *PostLoader) describeImage(slug string, asset Asset) (*Image, error) {
+	data, err := fs.ReadFile(pl.fs, asset.SourcePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	// Posts sharing an image give it different URLs, so each needs an entry.
+	key := "image:" + slug + ":" + asset.SourcePath
+	inputHash := cache.HashJSON([]interface{}{cache.HashBytes(data), asset.URL, pl.imageWidths, pl.imageFormats})
+	var image Image
+	if pl.cache.LookupAggregate(key, inputHash, &image) {
+		return &image, nil
+	}
+
+	decoded, err := imaging.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	bounds := decoded.Bounds()
+	image = Image{URL: asset.URL, Width: bounds.Dx(), Height: bounds.Dy()}
+
+	image.Placeholder, err = imaging.Placeholder(decoded)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, format := range pl.imageFormats {
+		for _, width := range pl.imageWidths {
+			if width >= image.Width {
+				continue
+			}
+			name := VariantName(asset.Name, width, format)
+			image.Variants = append(image.Variants, ImageVariant{
+				URL:    MediaURL(slug, name),
+				Width:  width,
+				Height: imaging.ScaledHeight(image.Width, image.Height, width),
+				Format: format,
+			})
+		}
+	}
+
+	if err := pl.cache.StoreAggregate(key, inputHash, image); err != nil {
+		return nil, err
+	}
+	return &image, nil
+}
+
+// VariantName returns the name of the variant of the asset called name
+// resized to width in format. The name of the original, extension included,
+// is kept so that photo.png and photo.jpg in one bundle get different
+// variants, such as photo.png-480w.webp and photo.jpg-480w.webp.
+func VariantName(name string, width int, format string) string {
+	return fmt.Sprintf("%s-%dw%s", name, width, imaging.Extension(format))
+}
+
+// srcset lists the variants of image in format, followed by the original
+// when it is in that format too, in the form of the HTML srcset attribute.
+func srcset(image *Image, format string, withOriginal bool) string {
+	var candidates []string
+	for _, variant := range image.Variants {
+		if variant.Format == format {
+			candidates = append(candidates, fmt.Sprintf("%s %dw", variant.URL, variant.Width))
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	if withOriginal {
+		candidates = append(candidates, fmt.Sprintf("%s %dw", image.URL, image.Width))
+	}
+	return strings.Join(candidates, ", ")
+}
+
+// variantFormats returns the formats image has variants in, the one for the
+// <img> fallback first: JPEG when there are JPEG variants, as every browser
+// can show it.
+func variantFormats(image *Image) []string {
+	var formats []string
+	seen := make(map[string]bool)
+	for _, variant := range image.Variants {
+		if seen[variant.Format] {
+			continue
+		}
+		seen[variant.Format] = true
+		if variant.Format == config.ImageFormatJPEG {
+			formats = append([]string{variant.Format}, formats...)
+		} else {
+			formats = append(formats, variant.Format)
+		}
+	}
+	return formats
+}
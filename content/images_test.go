@@ -0,0 +1,184 @@
+package content
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tech-arch1tect/mantle/cache"
+	"github.com/tech-arch1tect/mantle/config"
+)
+
+func TestVariantName(t *testing.T) {
+	tests := []struct {
+		name   string
+		width  int
+		format string
+		want   string
+	}{
+		{"photo.png", 480, config.ImageFormatWebP, "photo.png-480w.webp"},
+		{"photo.jpg", 480, config.ImageFormatWebP, "photo.jpg-480w.webp"},
+		{"photo.png", 960, config.ImageFormatJPEG, "photo.png-960w.jpg"},
+		{"images/shot.webp", 1440, config.ImageFormatJPEG, "images/shot.webp-1440w.jpg"},
+	}
+	for _, tt := range tests {
+		if got := VariantName(tt.name, tt.width, tt.format); got != tt.want {
+			t.Errorf("VariantName(%q, %d, %q) = %q, want %q", tt.name, tt.width, tt.format, got, tt.want)
+		}
+	}
+}
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, 0, color.RGBA{R: 200, A: 255})
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// loadTestPosts writes files into a temporary CONTENT_DIR and loads them with
+// image processing enabled.
+func loadTestPosts(t *testing.T, files map[string][]byte) ([]Post, []Issue) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.NewConfig()
+	cfg.ContentDir = dir
+	cfg.ProcessImages = true
+	cfg.ImageWidths = "4"
+	cfg.ImageFormats = "webp"
+	publishFilter, err := NewPublishFilter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loader := NewPostLoader(cfg, publishFilter, cache.NewMemoryBuildCache(cfg))
+	loader.SetLogger(log.New(io.Discard, "", 0))
+
+	posts, err := loader.LoadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return posts, loader.Issues()
+}
+
+func TestBundleVariantsDoNotCollide(t *testing.T) {
+	posts, issues := loadTestPosts(t, map[string][]byte{
+		"post/index.md":          []byte("---\ntitle: Post\nauthor: A\ndate: 2024-01-01\n---\nBody\n"),
+		"post/photo.png":         encodePNG(t, 8, 8),
+		"post/photo.jpg":         encodePNG(t, 8, 8),
+		"post/other.png":         encodePNG(t, 8, 8),
+		"post/other.png-4w.webp": []byte("not generated"),
+	})
+	if len(posts) != 1 {
+		t.Fatalf("loaded %d posts, want 1", len(posts))
+	}
+
+	variants := make(map[string]string)
+	for _, asset := range posts[0].Assets {
+		if asset.Image == nil {
+			continue
+		}
+		for _, variant := range asset.Image.Variants {
+			if other, ok := variants[variant.URL]; ok {
+				t.Errorf("%s and %s both have the variant %s", other, asset.Name, variant.URL)
+			}
+			variants[variant.URL] = asset.Name
+		}
+	}
+
+	for url, want := range map[string]string{
+		"/media/post/photo.png-4w.webp": "photo.png",
+		"/media/post/photo.jpg-4w.webp": "photo.jpg",
+	} {
+		if got := variants[url]; got != want {
+			t.Errorf("variant %s belongs to %q, want %q", url, got, want)
+		}
+	}
+	if got, ok := variants["/media/post/other.png-4w.webp"]; ok {
+		t.Errorf("variant of %s overwrites the asset other.png-4w.webp", got)
+	}
+
+	reported := false
+	for _, issue := range issues {
+		reported = reported || issue.Kind == IssueInvalidImage && strings.Contains(issue.Message, "other.png-4w.webp")
+	}
+	if !reported {
+		t.Errorf("the skipped variant of other.png is not reported, issues: %v", issues)
+	}
+}
+
+func TestCoverOutsideBundleGetsUniqueName(t *testing.T) {
+	posts, _ := loadTestPosts(t, map[string][]byte{
+		"post/index.md":    []byte("---\ntitle: Post\nauthor: A\ndate: 2024-01-01\ncoverImage: ../shared/cover.png\n---\nBody\n"),
+		"post/cover.png":   encodePNG(t, 2, 2),
+		"shared/cover.png": encodePNG(t, 2, 2),
+	})
+	if len(posts) != 1 {
+		t.Fatalf("loaded %d posts, want 1", len(posts))
+	}
+
+	urls := make(map[string]string)
+	for _, asset := range posts[0].Assets {
+		if other, ok := urls[asset.URL]; ok {
+			t.Errorf("%s and %s are both published as %s", other, asset.SourcePath, asset.URL)
+		}
+		urls[asset.URL] = asset.SourcePath
+	}
+	if got := urls["/media/post/cover-2.png"]; got != "shared/cover.png" {
+		t.Errorf("/media/post/cover-2.png is %q, want shared/cover.png", got)
+	}
+	if posts[0].Cover == nil || posts[0].Cover.URL != "/media/post/cover-2.png" {
+		t.Errorf("cover = %+v, want /media/post/cover-2.png", posts[0].Cover)
+	}
+}
+
+func TestImagesReferencedOutsideBundles(t *testing.T) {
+	posts, issues := loadTestPosts(t, map[string][]byte{
+		"second.md":        []byte("---\ntitle: Second\nauthor: A\ndate: 2024-01-01\n---\n![Chart](images/chart.png)\n\n![](missing.png)\n"),
+		"images/chart.png": encodePNG(t, 8, 4),
+	})
+	if len(posts) != 1 {
+		t.Fatalf("loaded %d posts, want 1", len(posts))
+	}
+	post := posts[0]
+
+	if len(post.Assets) != 1 || post.Assets[0].Image == nil {
+		t.Fatalf("assets = %+v, want chart.png with image details", post.Assets)
+	}
+	image := post.Assets[0].Image
+	if image.URL != "/media/second/chart.png" || image.Width != 8 || image.Height != 4 || len(image.Variants) != 1 {
+		t.Errorf("image = %+v, want 8x4 /media/second/chart.png with one variant", image)
+	}
+	if !strings.Contains(post.Markdown, "![Chart](/media/second/chart.png)") {
+		t.Errorf("markdown was not rewritten to the published image:\n%s", post.Markdown)
+	}
+
+	reported := false
+	for _, issue := range issues {
+		reported = reported || issue.Kind == IssueBrokenLink && issue.Line == 8 && strings.Contains(issue.Message, "missing.png")
+	}
+	if !reported {
+		t.Errorf("missing.png is not reported as a broken link on line 8, issues: %v", issues)
+	}
+}
@@ -0,0 +1,61 @@
+package content
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of Issue reported while loading content.
+const (
+	IssueLoadError          = "load-error"
+	IssueInvalidFrontMatter = "invalid-frontmatter"
+	IssueMissingField       = "missing-field"
+	IssueInvalidDate        = "invalid-date"
+	IssueSlugCollision      = "slug-collision"
+	IssueBrokenLink         = "broken-link"
+	IssueInvalidImage       = "invalid-image"
+)
+
+// Issue is a problem with a content file that Mantle worked around, by
+// skipping the file or falling back to a default, instead of failing the build.
+// Line is 1-based, or 0 when the issue is not tied to a line of the file.
+type Issue struct {
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+func (i Issue) String() string {
+	location := i.File
+	if i.Line > 0 {
+		location = fmt.Sprintf("%s:%d", i.File, i.Line)
+	}
+	return fmt.Sprintf("%s: [%s] %s", location, i.Kind, i.Message)
+}
+
+func (pl *PostLoader) report(file, kind, format string, args ...interface{}) {
+	pl.reportLine(file, 0, kind, format, args...)
+}
+
+func (pl *PostLoader) reportLine(file string, line int, kind, format string, args ...interface{}) {
+	issue := Issue{File: file, Line: line, Kind: kind, Message: fmt.Sprintf(format, args...)}
+	pl.issues = append(pl.issues, issue)
+	pl.logger.Printf("Warning: %s", issue)
+}
+
+// Issues returns the problems found by the last call to LoadAll.
+func (pl *PostLoader) Issues() []Issue {
+	return pl.issues
+}
+
+func issueKind(err error) string {
+	switch {
+	case errors.Is(err, ErrNoFrontMatter), errors.Is(err, ErrInvalidFrontMatter):
+		return IssueInvalidFrontMatter
+	case errors.Is(err, ErrInvalidDate):
+		return IssueInvalidDate
+	default:
+		return IssueLoadError
+	}
+}
@@ -0,0 +1,267 @@
+package content
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var (
+	// markdownLinkPattern matches the destination of an inline link to a
+	// markdown file, such as the "other-post.md#setup" in
+	// [setup](other-post.md#setup "Setup").
+	markdownLinkPattern = regexp.MustCompile(`\]\(\s*<?([^()<>\s]+\.md)(#[^()<>\s]*)?>?(?:\s+(?:"[^"]*"|'[^']*'))?\s*\)`)
+	// wikiLinkPattern matches [[target]], [[target#fragment]] and
+	// [[target|label]].
+	wikiLinkPattern = regexp.MustCompile(`\[\[([^\[\]|#]+)(#[^\[\]|]*)?(?:\|([^\[\]]+))?\]\]`)
+)
+
+// linkTargets finds loaded posts by the names authors use to link to them.
+type linkTargets struct {
+	bySource map[string]int
+	byName   map[string]int
+}
+
+func newLinkTargets(posts []Post) *linkTargets {
+	targets := &linkTargets{
+		bySource: make(map[string]int, len(posts)),
+		byName:   make(map[string]int, len(posts)),
+	}
+
+	// Wiki links match a slug first, then the path without .md, then the file
+	// name without .md. Names shared by several posts are ambiguous and match
+	// nothing (-1).
+	for i, post := range posts {
+		targets.bySource[post.SourcePath] = i
+		targets.byName[post.FrontMatter.Slug] = i
+	}
+	for i, post := range posts {
+		name := strings.TrimSuffix(post.SourcePath, ".md")
+		if _, ok := targets.byName[name]; !ok {
+			targets.byName[name] = i
+		}
+	}
+	baseNames := make(map[string]int)
+	for i, post := range posts {
+		name := strings.TrimSuffix(path.Base(post.SourcePath), ".md")
+		if _, ok := baseNames[name]; ok {
+			baseNames[name] = -1
+			continue
+		}
+		baseNames[name] = i
+	}
+	for name, i := range baseNames {
+		if _, ok := targets.byName[name]; !ok {
+			targets.byName[name] = i
+		}
+	}
+	return targets
+}
+
+func (lt *linkTargets) wiki(name string) (int, bool) {
+	i, ok := lt.byName[strings.TrimSuffix(name, ".md")]
+	return i, ok && i >= 0
+}
+
+// resolveLinks rewrites relative links to markdown files and [[wiki]] links
+// in the body and excerpt of every post to the permalink of the post they
+// point to, and records the slugs each post links to. Links that point to no
+// loaded post are reported as issues.
+func (pl *PostLoader) resolveLinks(posts []Post) {
+	targets := newLinkTargets(posts)
+
+	for i := range posts {
+		post := &posts[i]
+		linked := make(map[string]bool)
+		post.Links = nil
+
+		record := func(target int) {
+			slug := posts[target].FrontMatter.Slug
+			if target != i && !linked[slug] {
+				linked[slug] = true
+				post.Links = append(post.Links, slug)
+			}
+		}
+		post.Markdown = pl.rewriteLinks(post, post.Markdown, posts, targets, record)
+		post.Excerpt = pl.rewriteLinks(post, post.Excerpt, posts, targets, nil)
+	}
+}
+
+// rewriteLinks rewrites the links in source, which is part of post. When
+// record is nil, broken links are not reported, so that an excerpt taken from
+// the body is not reported twice.
+func (pl *PostLoader) rewriteLinks(post *Post, source string, posts []Post, targets *linkTargets, record func(int)) string {
+	if !strings.Contains(source, ".md") && !strings.Contains(source, "[[") {
+		return source
+	}
+
+	return rewriteOutsideCode(source, func(text string, n int) string {
+		line := post.BodyOffset + n + 1
+		text = pl.rewriteMarkdownLinks(post, text, line, posts, targets, record)
+		return pl.rewriteWikiLinks(post, text, line, posts, targets, record)
+	})
+}
+
+func (pl *PostLoader) rewriteMarkdownLinks(post *Post, text string, line int, posts []Post, targets *linkTargets, record func(int)) string {
+	var b strings.Builder
+	last := 0
+	for _, match := range markdownLinkPattern.FindAllStringSubmatchIndex(text, -1) {
+		destination := text[match[2]:match[3]]
+		file, ok := markdownTarget(post.SourcePath, destination)
+		if !ok {
+			continue
+		}
+
+		target, ok := targets.bySource[file]
+		if !ok {
+			if record != nil {
+				pl.reportLine(post.SourcePath, line, IssueBrokenLink, "link to %q points to %s, which %s",
+					destination, file, pl.missingReason(file))
+			}
+			continue
+		}
+		if record != nil {
+			record(target)
+		}
+
+		b.WriteString(text[last:match[2]])
+		b.WriteString(posts[target].Permalink)
+		last = match[3]
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+func (pl *PostLoader) rewriteWikiLinks(post *Post, text string, line int, posts []Post, targets *linkTargets, record func(int)) string {
+	var b strings.Builder
+	last := 0
+	for _, match := range wikiLinkPattern.FindAllStringSubmatchIndex(text, -1) {
+		// ![[...]] embeds are left alone.
+		if match[0] > 0 && text[match[0]-1] == '!' {
+			continue
+		}
+		name := strings.TrimSpace(text[match[2]:match[3]])
+		fragment, label := "", ""
+		if match[4] >= 0 {
+			fragment = text[match[4]:match[5]]
+		}
+		if match[6] >= 0 {
+			label = strings.TrimSpace(text[match[6]:match[7]])
+		}
+
+		b.WriteString(text[last:match[0]])
+		last = match[1]
+
+		target, ok := targets.wiki(name)
+		if !ok {
+			if record != nil {
+				reason := "does not match the slug, path or file name of any post"
+				if pl.fileExists(name + ".md") {
+					reason = fmt.Sprintf("points to %s.md, which %s", name, pl.missingReason(name+".md"))
+				}
+				pl.reportLine(post.SourcePath, line, IssueBrokenLink, "link %s %s", text[match[0]:match[1]], reason)
+			}
+			if label == "" {
+				label = name
+			}
+			b.WriteString(label)
+			continue
+		}
+		if record != nil {
+			record(target)
+		}
+
+		if label == "" {
+			label = posts[target].FrontMatter.Title
+		}
+		fmt.Fprintf(&b, "[%s](%s%s)", escapeLinkText(label), posts[target].Permalink, fragment)
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+func (pl *PostLoader) missingReason(file string) string {
+	if pl.fileExists(file) {
+		return "is not published"
+	}
+	return "does not exist"
+}
+
+func (pl *PostLoader) fileExists(file string) bool {
+	_, err := fs.Stat(pl.fs, file)
+	return err == nil
+}
+
+// markdownTarget resolves a relative link to a markdown file against the
+// directory of the linking post. It reports false for any other link.
+func markdownTarget(from, destination string) (string, bool) {
+	u, err := url.Parse(destination)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" || strings.HasPrefix(u.Path, "/") {
+		return "", false
+	}
+	if path.Ext(u.Path) != ".md" {
+		return "", false
+	}
+	return path.Clean(path.Join(path.Dir(from), u.Path)), true
+}
+
+// rewriteOutsideCode applies rewrite to every part of a markdown source that is
+// not inside a fenced code block or a code span, passing the 0-based line the
+// text is on.
+func rewriteOutsideCode(source string, rewrite func(text string, line int) string) string {
+	lines := strings.Split(source, "\n")
+	fence := ""
+	for n, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+
+		lines[n] = outsideCodeSpans(line, func(text string) string {
+			return rewrite(text, n)
+		})
+	}
+	return strings.Join(lines, "\n")
+}
+
+func outsideCodeSpans(line string, rewrite func(string) string) string {
+	if !strings.Contains(line, "`") {
+		return rewrite(line)
+	}
+
+	var b strings.Builder
+	for line != "" {
+		start := strings.Index(line, "`")
+		if start < 0 {
+			b.WriteString(rewrite(line))
+			break
+		}
+		b.WriteString(rewrite(line[:start]))
+
+		ticks := len(line[start:]) - len(strings.TrimLeft(line[start:], "`"))
+		delimiter := line[start : start+ticks]
+		end := strings.Index(line[start+ticks:], delimiter)
+		if end < 0 {
+			b.WriteString(line[start:])
+			break
+		}
+		end += start + 2*ticks
+		b.WriteString(line[start:end])
+		line = line[end:]
+	}
+	return b.String()
+}
+
+func escapeLinkText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`).Replace(text)
+}
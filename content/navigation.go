@@ -0,0 +1,34 @@
+package content
+
+// @Description Short summary of another post, for navigation links
+type PostSummary struct {
+	Slug      string `json:"slug" example:"advanced-go-patterns"`
+	Title     string `json:"title" example:"Advanced Go Patterns"`
+	Date      string `json:"date" example:"2024-01-20"`
+	Permalink string `json:"permalink" example:"/advanced-go-patterns"`
+}
+
+func NewPostSummary(post Post) PostSummary {
+	return PostSummary{
+		Slug:      post.FrontMatter.Slug,
+		Title:     post.FrontMatter.Title,
+		Date:      post.FrontMatter.Date,
+		Permalink: post.Permalink,
+	}
+}
+
+// @Description Position of a post within its series, with the posts before and after it
+type SeriesNav struct {
+	Name  string       `json:"name" example:"Learning Go"`
+	Slug  string       `json:"slug" example:"learning-go"`
+	Index int          `json:"index" example:"2"`
+	Total int          `json:"total" example:"5"`
+	Prev  *PostSummary `json:"prev,omitempty"`
+	Next  *PostSummary `json:"next,omitempty"`
+}
+
+// @Description Older and newer neighbours of a post within a category or tag
+type PostNav struct {
+	Previous *PostSummary `json:"previous,omitempty"`
+	Next     *PostSummary `json:"next,omitempty"`
+}
@@ -0,0 +1,43 @@
+package content
+
+import (
+	"strings"
+	"time"
+
+	"github.com/tech-arch1tect/mantle/config"
+)
+
+type PermalinkBuilder struct {
+	template   string
+	dateFormat string
+}
+
+func NewPermalinkBuilder(cfg *config.Config) *PermalinkBuilder {
+	return &PermalinkBuilder{
+		template:   cfg.Permalink,
+		dateFormat: cfg.DateFormat,
+	}
+}
+
+func (pb *PermalinkBuilder) Build(fm FrontMatter) string {
+	year, month, day := "0000", "00", "00"
+	if date, err := time.Parse(pb.dateFormat, fm.Date); err == nil {
+		year = date.Format("2006")
+		month = date.Format("01")
+		day = date.Format("02")
+	}
+
+	replacer := strings.NewReplacer(
+		"{year}", year,
+		"{month}", month,
+		"{day}", day,
+		"{slug}", fm.Slug,
+		"{category}", fm.Category,
+	)
+
+	permalink := replacer.Replace(pb.template)
+	for strings.Contains(permalink, "//") {
+		permalink = strings.ReplaceAll(permalink, "//", "/")
+	}
+	return permalink
+}
@@ -0,0 +1,534 @@
+package content
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/tech-arch1tect/mantle/cache"
+	"github.com/tech-arch1tect/mantle/config"
+	"github.com/tech-arch1tect/mantle/internal/glob"
+)
+
+var (
+	ErrNoFrontMatter      = errors.New("no frontmatter found")
+	ErrInvalidFrontMatter = errors.New("invalid frontmatter format")
+	ErrNotPublished       = errors.New("post is not published")
+	ErrInvalidDate        = errors.New("invalid date")
+)
+
+// @Description Post frontmatter containing metadata
+type FrontMatter struct {
+	Title       string   `yaml:"title" json:"title" example:"Getting Started with Go"`
+	Author      string   `yaml:"author" json:"author" example:"John Doe"`
+	Date        string   `yaml:"date" json:"date" example:"2024-01-15"`
+	Tags        []string `yaml:"tags" json:"tags" example:"golang,tutorial,beginner"`
+	Category    string   `yaml:"category,omitempty" json:"category,omitempty" example:"tech/tutorials"`
+	Excerpt     string   `yaml:"excerpt,omitempty" json:"excerpt,omitempty" example:"Learn the basics of Go programming language"`
+	Slug        string   `yaml:"slug,omitempty" json:"slug,omitempty" example:"getting-started-with-go"`
+	Draft       bool     `yaml:"draft,omitempty" json:"draft,omitempty" example:"false"`
+	PublishDate string   `yaml:"publishDate,omitempty" json:"publishDate,omitempty" example:"2024-01-15"`
+	ExpiryDate  string   `yaml:"expiryDate,omitempty" json:"expiryDate,omitempty" example:"2025-01-15"`
+	CoverImage  string   `yaml:"coverImage,omitempty" json:"coverImage,omitempty" example:"images/cover.jpg"`
+	Series      string   `yaml:"series,omitempty" json:"series,omitempty" example:"Learning Go"`
+	SeriesOrder int      `yaml:"seriesOrder,omitempty" json:"seriesOrder,omitempty" example:"1"`
+}
+
+func (fm FrontMatter) Validate() []string {
+	var warnings []string
+	if strings.TrimSpace(fm.Title) == "" {
+		warnings = append(warnings, "title is empty")
+	}
+	if strings.TrimSpace(fm.Author) == "" {
+		warnings = append(warnings, "author is empty")
+	}
+	if strings.TrimSpace(fm.Date) == "" {
+		warnings = append(warnings, "date is empty")
+	}
+	return warnings
+}
+
+// @Description Complete blog post including markdown content and frontmatter
+type Post struct {
+	Permalink     string             `json:"permalink" example:"/getting-started-with-go"`
+	Markdown      string             `json:"markdown" example:"# Getting Started with Go\n\nThis is the content..."`
+	HTML          string             `json:"html,omitempty" example:"<h1 id=\"getting-started-with-go\">Getting Started with Go</h1>\n<p>This is the content...</p>"`
+	FrontMatter   FrontMatter        `json:"frontmatter"`
+	Excerpt       string             `json:"excerpt" example:"This is a brief excerpt of the post..."`
+	ExcerptHTML   string             `json:"excerptHtml,omitempty" example:"<p>This is a brief excerpt of the post...</p>"`
+	ReadingTime   int                `json:"readingTime" example:"5"`
+	CodeLanguages []string           `json:"codeLanguages,omitempty" example:"go,bash"`
+	TOC           []TOCEntry         `json:"toc,omitempty"`
+	Links         []string           `json:"links,omitempty" example:"advanced-go-patterns"`
+	Assets        []Asset            `json:"assets,omitempty"`
+	Cover         *Image             `json:"cover,omitempty"`
+	Series        *SeriesNav         `json:"series,omitempty"`
+	Previous      *PostSummary       `json:"previous,omitempty"`
+	Next          *PostSummary       `json:"next,omitempty"`
+	CategoryNav   *PostNav           `json:"categoryNav,omitempty"`
+	TagNav        map[string]PostNav `json:"tagNav,omitempty"`
+	SourcePath    string             `json:"-"`
+	// BodyOffset is the number of lines in the source file before Markdown.
+	BodyOffset int `json:"-"`
+	// HTMLOnly leaves markdown and excerpt out of the JSON, for
+	// CONTENT_FORMAT=html.
+	HTMLOnly bool `json:"-"`
+}
+
+func (p Post) MarshalJSON() ([]byte, error) {
+	type post Post
+	if !p.HTMLOnly {
+		return json.Marshal(post(p))
+	}
+	return json.Marshal(struct {
+		post
+		Markdown string `json:"markdown,omitempty"`
+		Excerpt  string `json:"excerpt,omitempty"`
+	}{post: post(p), Markdown: p.Markdown, Excerpt: p.Excerpt})
+}
+
+// @Description Post preview containing frontmatter, excerpt, and reading time
+type PostPreview struct {
+	Permalink   string      `json:"permalink" example:"/getting-started-with-go"`
+	FrontMatter FrontMatter `json:"frontmatter"`
+	Excerpt     string      `json:"excerpt" example:"This is a brief excerpt of the post..."`
+	ExcerptHTML string      `json:"excerptHtml,omitempty" example:"<p>This is a brief excerpt of the post...</p>"`
+	ReadingTime int         `json:"readingTime" example:"5"`
+	Cover       *Image      `json:"cover,omitempty"`
+	// HTMLOnly leaves excerpt out of the JSON, for CONTENT_FORMAT=html.
+	HTMLOnly bool `json:"-"`
+}
+
+func (p PostPreview) MarshalJSON() ([]byte, error) {
+	type preview PostPreview
+	if !p.HTMLOnly {
+		return json.Marshal(preview(p))
+	}
+	return json.Marshal(struct {
+		preview
+		Excerpt string `json:"excerpt,omitempty"`
+	}{preview: preview(p), Excerpt: p.Excerpt})
+}
+
+func NewPostPreview(post Post) PostPreview {
+	return PostPreview{
+		Permalink:   post.Permalink,
+		FrontMatter: post.FrontMatter,
+		Excerpt:     post.Excerpt,
+		ExcerptHTML: post.ExcerptHTML,
+		ReadingTime: post.ReadingTime,
+		Cover:       post.Cover,
+		HTMLOnly:    post.HTMLOnly,
+	}
+}
+
+type PostLoaderInterface interface {
+	LoadAll() ([]Post, error)
+	Count() (int, error)
+}
+
+type PostLoader struct {
+	contentDir            string
+	logger                *log.Logger
+	fs                    fs.FS
+	averageWordsPerMinute int
+	include               []string
+	exclude               []string
+	categoryFromPath      bool
+	publishFilter         *PublishFilter
+	tocExtractor          *TOCExtractor
+	permalinks            *PermalinkBuilder
+	cache                 *cache.BuildCache
+	dateFormat            string
+	processImages         bool
+	imageWidths           []int
+	imageFormats          []string
+	issues                []Issue
+}
+
+func NewPostLoader(cfg *config.Config, publishFilter *PublishFilter, cache *cache.BuildCache) *PostLoader {
+	return &PostLoader{
+		contentDir:            cfg.ContentDir,
+		logger:                log.New(os.Stdout, "[PostLoader] ", log.LstdFlags),
+		fs:                    os.DirFS(cfg.ContentDir),
+		averageWordsPerMinute: cfg.AverageWordsPerMinute,
+		include:               config.SplitList(cfg.ContentInclude),
+		exclude:               config.SplitList(cfg.ContentExclude),
+		categoryFromPath:      cfg.CategoryFromPath,
+		publishFilter:         publishFilter,
+		tocExtractor:          NewTOCExtractor(cfg),
+		permalinks:            NewPermalinkBuilder(cfg),
+		cache:                 cache,
+		dateFormat:            cfg.DateFormat,
+		processImages:         cfg.ProcessImages,
+		imageWidths:           cfg.ImageWidthList(),
+		imageFormats:          config.SplitList(cfg.ImageFormats),
+	}
+}
+
+// SetLogger replaces the logger that skipped posts and issues are logged to.
+func (pl *PostLoader) SetLogger(logger *log.Logger) {
+	pl.logger = logger
+}
+
+func (pl *PostLoader) LoadAll() ([]Post, error) {
+	files, err := pl.listMarkdownFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list markdown files: %w", err)
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no markdown files found in %s", pl.contentDir)
+	}
+
+	posts := make([]Post, 0, len(files))
+	usedSlugs := make(map[string]string)
+	pl.issues = nil
+
+	for _, file := range files {
+		post, err := pl.loadPost(file, usedSlugs)
+		if errors.Is(err, ErrNotPublished) {
+			pl.logger.Printf("skipping post %s: %v", file, err)
+			continue
+		}
+		if err != nil {
+			pl.report(file, issueKind(err), "skipped: %v", err)
+			continue
+		}
+		posts = append(posts, post)
+	}
+
+	pl.resolveLinks(posts)
+	return posts, nil
+}
+
+func (pl *PostLoader) listMarkdownFiles() ([]string, error) {
+	var mdFiles []string
+
+	err := fs.WalkDir(pl.fs, ".", func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == "." {
+			return nil
+		}
+
+		if entry.IsDir() {
+			if glob.MatchAny(pl.exclude, p) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if !strings.HasSuffix(entry.Name(), ".md") {
+			return nil
+		}
+		if !glob.MatchAny(pl.include, p) || glob.MatchAny(pl.exclude, p) {
+			return nil
+		}
+
+		mdFiles = append(mdFiles, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return mdFiles, nil
+}
+
+// CategoryDirs lists the directories below CONTENT_DIR that posts take their
+// category from when CATEGORY_FROM_PATH is set. Hidden and excluded
+// directories and page bundles are left out, along with everything below them.
+func (pl *PostLoader) CategoryDirs() ([]string, error) {
+	files, err := pl.listMarkdownFiles()
+	if err != nil {
+		return nil, err
+	}
+	bundles := make(map[string]bool)
+	for _, file := range files {
+		if IsBundle(file) {
+			bundles[path.Dir(file)] = true
+		}
+	}
+
+	var dirs []string
+	err = fs.WalkDir(pl.fs, ".", func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == "." || !entry.IsDir() {
+			return nil
+		}
+		if strings.HasPrefix(entry.Name(), ".") || glob.MatchAny(pl.exclude, p) || bundles[p] {
+			return fs.SkipDir
+		}
+		dirs = append(dirs, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dirs, nil
+}
+
+func (pl *PostLoader) loadPost(file string, usedSlugs map[string]string) (Post, error) {
+	content, err := fs.ReadFile(pl.fs, file)
+	if err != nil {
+		return Post{}, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	hash := cache.HashBytes(content)
+	var post Post
+	if !pl.cache.LookupSource(file, hash, &post) {
+		post, err = pl.parsePost(file, string(content))
+		if err != nil {
+			return Post{}, err
+		}
+		if err := pl.cache.StoreSource(file, hash, post); err != nil {
+			return Post{}, err
+		}
+	}
+	post.SourcePath = file
+	if index := strings.Index(string(content), post.Markdown); index >= 0 {
+		post.BodyOffset = strings.Count(string(content[:index]), "\n")
+	}
+
+	reason, err := pl.publishFilter.Check(post.FrontMatter)
+	if err != nil {
+		return Post{}, fmt.Errorf("failed to check publication state: %w", err)
+	}
+	if reason != "" {
+		return Post{}, fmt.Errorf("%w: %s", ErrNotPublished, reason)
+	}
+
+	for _, warning := range post.FrontMatter.Validate() {
+		pl.report(file, IssueMissingField, "%s", warning)
+	}
+	if post.FrontMatter.Date != "" {
+		if _, err := time.Parse(pl.dateFormat, post.FrontMatter.Date); err != nil {
+			pl.report(file, IssueInvalidDate, "date %q does not match DATE_FORMAT %q, so the post sorts as if published in 1970",
+				post.FrontMatter.Date, pl.dateFormat)
+		}
+	}
+	// With BUILD_FUTURE the publish filter does not look at publishDate, so
+	// check it here as well.
+	if post.FrontMatter.PublishDate != "" {
+		if _, err := time.Parse(pl.dateFormat, post.FrontMatter.PublishDate); err != nil {
+			pl.report(file, IssueInvalidDate, "publishDate %q does not match DATE_FORMAT %q",
+				post.FrontMatter.PublishDate, pl.dateFormat)
+		}
+	}
+
+	slug := pl.ensureUniqueSlug(post.FrontMatter.Slug, usedSlugs)
+	if slug != post.FrontMatter.Slug {
+		pl.report(file, IssueSlugCollision, "slug %q is already used by %s, published as %q instead",
+			post.FrontMatter.Slug, usedSlugs[post.FrontMatter.Slug], slug)
+	}
+	post.FrontMatter.Slug = slug
+	usedSlugs[slug] = file
+	post.Permalink = pl.permalinks.Build(post.FrontMatter)
+
+	if IsBundle(file) {
+		post.Assets, err = pl.bundleAssets(file, slug)
+		if err != nil {
+			return Post{}, fmt.Errorf("failed to list bundle assets: %w", err)
+		}
+		post.Markdown = rewriteAssetReferences(&post, post.Markdown)
+		post.Excerpt = rewriteAssetReferences(&post, post.Excerpt)
+	}
+	pl.addCoverAsset(&post)
+	if pl.processImages {
+		pl.addReferencedImages(&post)
+		pl.describeImages(&post)
+	}
+	post.Cover = cover(&post)
+
+	return post, nil
+}
+
+func (pl *PostLoader) parsePost(file, content string) (Post, error) {
+	frontMatter, body, err := pl.parseFrontMatter(content, file)
+	if err != nil {
+		return Post{}, fmt.Errorf("failed to parse frontmatter: %w", err)
+	}
+
+	if frontMatter.Category == "" && pl.categoryFromPath {
+		dir := path.Dir(file)
+		if IsBundle(file) {
+			dir = path.Dir(dir)
+		}
+		if dir != "." {
+			frontMatter.Category = dir
+		}
+	}
+
+	if frontMatter.Slug == "" {
+		frontMatter.Slug = Slugify(frontMatter.Title)
+	}
+
+	excerpt := pl.generateExcerpt(frontMatter, body)
+	readingTime := pl.calculateReadingTime(body)
+	toc := pl.tocExtractor.Extract(body)
+
+	return Post{
+		Markdown:    body,
+		FrontMatter: frontMatter,
+		Excerpt:     excerpt,
+		ReadingTime: readingTime,
+		TOC:         toc,
+	}, nil
+}
+
+func (pl *PostLoader) ensureUniqueSlug(baseSlug string, usedSlugs map[string]string) string {
+	slug := baseSlug
+	counter := 1
+
+	for usedSlugs[slug] != "" {
+		slug = fmt.Sprintf("%s-%d", baseSlug, counter)
+		counter++
+	}
+
+	return slug
+}
+
+// Slugify turns a title into a lower-case, hyphen-separated name suitable for
+// URLs and file names.
+func Slugify(title string) string {
+	slug := strings.ToLower(title)
+
+	reg := regexp.MustCompile(`[^a-z0-9\s-]`)
+	slug = reg.ReplaceAllString(slug, "")
+
+	spaceReg := regexp.MustCompile(`\s+`)
+	slug = spaceReg.ReplaceAllString(slug, "-")
+
+	dashReg := regexp.MustCompile(`-+`)
+	slug = dashReg.ReplaceAllString(slug, "-")
+
+	slug = strings.Trim(slug, "-")
+
+	if slug == "" {
+		slug = "untitled"
+	}
+
+	return slug
+}
+
+func (pl *PostLoader) calculateReadingTime(markdown string) int {
+	words := strings.Fields(strings.ReplaceAll(markdown, "\n", " "))
+	wordCount := len(words)
+
+	averageWordsPerMinute := pl.averageWordsPerMinute
+	readingTimeMinutes := (wordCount + averageWordsPerMinute - 1) / averageWordsPerMinute
+
+	if readingTimeMinutes < 1 {
+		return 1
+	}
+
+	return readingTimeMinutes
+}
+
+func (pl *PostLoader) parseFrontMatter(content, filename string) (FrontMatter, string, error) {
+	var fm FrontMatter
+
+	if !strings.HasPrefix(content, "---") {
+		return fm, content, ErrNoFrontMatter
+	}
+
+	parts := strings.SplitN(content, "---", 3)
+	if len(parts) < 3 {
+		return fm, content, ErrInvalidFrontMatter
+	}
+
+	if err := yaml.Unmarshal([]byte(parts[1]), &fm); err != nil {
+		return fm, content, fmt.Errorf("%w: %v", ErrInvalidFrontMatter, err)
+	}
+
+	return fm, strings.TrimSpace(parts[2]), nil
+}
+
+func (pl *PostLoader) generateExcerpt(fm FrontMatter, body string) string {
+	if fm.Excerpt != "" {
+		return fm.Excerpt
+	}
+
+	excerptSeparator := "<!--more-->"
+	if idx := strings.Index(body, excerptSeparator); idx != -1 {
+		return strings.TrimSpace(body[:idx])
+	}
+
+	return pl.autoGenerateExcerpt(body)
+}
+
+func (pl *PostLoader) autoGenerateExcerpt(body string) string {
+	const maxWords = 200
+	const maxChars = 1000
+
+	lines := strings.Split(body, "\n")
+	var cleanedLines []string
+	inCodeBlock := false
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") {
+			inCodeBlock = !inCodeBlock
+			continue
+		}
+
+		if inCodeBlock {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if trimmed == "" {
+			continue
+		}
+
+		cleanedLines = append(cleanedLines, line)
+	}
+
+	cleanedBody := strings.Join(cleanedLines, " ")
+
+	words := strings.Fields(cleanedBody)
+
+	var excerpt []string
+	charCount := 0
+
+	for i, word := range words {
+		if i >= maxWords || charCount+len(word)+1 > maxChars {
+			break
+		}
+		excerpt = append(excerpt, word)
+		charCount += len(word) + 1
+	}
+
+	result := strings.Join(excerpt, " ")
+
+	if len(words) > len(excerpt) {
+		result += "..."
+	}
+
+	return result
+}
+
+func (pl *PostLoader) Count() (int, error) {
+	files, err := pl.listMarkdownFiles()
+	if err != nil {
+		return 0, err
+	}
+	return len(files), nil
+}
@@ -0,0 +1,68 @@
+package content
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tech-arch1tect/mantle/config"
+)
+
+type PublishFilter struct {
+	now         time.Time
+	dateFormat  string
+	buildDrafts bool
+	buildFuture bool
+}
+
+func NewPublishFilter(cfg *config.Config) (*PublishFilter, error) {
+	now, err := cfg.BuildClock()
+	if err != nil {
+		return nil, err
+	}
+
+	return &PublishFilter{
+		now:         now,
+		dateFormat:  cfg.DateFormat,
+		buildDrafts: cfg.BuildDrafts,
+		buildFuture: cfg.BuildFuture,
+	}, nil
+}
+
+// Check returns an empty reason when the post should be published at the
+// build clock, or a short explanation of why it is being held back.
+func (pf *PublishFilter) Check(fm FrontMatter) (string, error) {
+	if fm.Draft && !pf.buildDrafts {
+		return "draft", nil
+	}
+
+	if fm.ExpiryDate != "" {
+		expiry, err := time.Parse(pf.dateFormat, fm.ExpiryDate)
+		if err != nil {
+			return "", fmt.Errorf("%w: expiryDate %q: %v", ErrInvalidDate, fm.ExpiryDate, err)
+		}
+		if !pf.now.Before(expiry) {
+			return fmt.Sprintf("expired on %s", fm.ExpiryDate), nil
+		}
+	}
+
+	if pf.buildFuture {
+		return "", nil
+	}
+
+	if fm.PublishDate != "" {
+		publishDate, err := time.Parse(pf.dateFormat, fm.PublishDate)
+		if err != nil {
+			return "", fmt.Errorf("%w: publishDate %q: %v", ErrInvalidDate, fm.PublishDate, err)
+		}
+		if publishDate.After(pf.now) {
+			return fmt.Sprintf("scheduled for %s", fm.PublishDate), nil
+		}
+		return "", nil
+	}
+
+	if date, err := time.Parse(pf.dateFormat, fm.Date); err == nil && date.After(pf.now) {
+		return fmt.Sprintf("dated in the future (%s)", fm.Date), nil
+	}
+
+	return "", nil
+}
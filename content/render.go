@@ -0,0 +1,229 @@
+package content
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"log"
+	"os"
+	"strconv"
+
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+
+	"github.com/tech-arch1tect/mantle/cache"
+	"github.com/tech-arch1tect/mantle/config"
+	"github.com/tech-arch1tect/mantle/internal/imaging"
+)
+
+type MarkdownRenderer struct {
+	config   *config.Config
+	logger   *log.Logger
+	markdown goldmark.Markdown
+	cache    *cache.BuildCache
+}
+
+func NewMarkdownRenderer(cfg *config.Config, cache *cache.BuildCache) *MarkdownRenderer {
+	extensions := []goldmark.Extender{extension.GFM}
+	if cfg.HighlightCode {
+		extensions = append(extensions, highlighting.NewHighlighting(
+			highlighting.WithStyle(cfg.HighlightStyle),
+			highlighting.WithFormatOptions(chromahtml.WithClasses(true)),
+			highlighting.WithWrapperRenderer(renderCodeBlockWrapper),
+		))
+	}
+
+	return &MarkdownRenderer{
+		config: cfg,
+		logger: log.New(os.Stdout, "[MarkdownRenderer] ", log.LstdFlags),
+		markdown: goldmark.New(
+			goldmark.WithExtensions(extensions...),
+			goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+			goldmark.WithRendererOptions(renderer.WithNodeRenderers(util.Prioritized(pictureRenderer{}, 500))),
+		),
+		cache: cache,
+	}
+}
+
+func (mr *MarkdownRenderer) Render(posts []Post) ([]Post, error) {
+	if !mr.config.RendersHTML() {
+		return posts, nil
+	}
+
+	rendered := make([]Post, 0, len(posts))
+	reused := 0
+	for _, post := range posts {
+		images := make(map[string]*Image)
+		for _, asset := range post.Assets {
+			if asset.Image != nil {
+				images[asset.URL] = asset.Image
+			}
+		}
+
+		key := cache.HashJSON([]interface{}{post.Markdown, post.Excerpt, images})
+		entry, cached := mr.cache.LookupRender(key)
+		if cached {
+			reused++
+		} else {
+			body, languages, err := mr.renderHTML(post.Markdown, images)
+			if err != nil {
+				return nil, fmt.Errorf("failed to render post %s: %w", post.FrontMatter.Slug, err)
+			}
+
+			excerptHTML, _, err := mr.renderHTML(post.Excerpt, images)
+			if err != nil {
+				return nil, fmt.Errorf("failed to render excerpt for post %s: %w", post.FrontMatter.Slug, err)
+			}
+
+			entry = cache.RenderEntry{HTML: body, ExcerptHTML: excerptHTML, CodeLanguages: languages}
+			mr.cache.StoreRender(key, entry)
+		}
+
+		post.HTML = entry.HTML
+		post.ExcerptHTML = entry.ExcerptHTML
+		post.CodeLanguages = entry.CodeLanguages
+		rendered = append(rendered, post)
+	}
+
+	mr.logger.Printf("Rendered HTML for %d post(s), %d reused from cache", len(rendered)-reused, reused)
+	return rendered, nil
+}
+
+// renderHTML renders source, returning the languages of its fenced code
+// blocks. Images found in images are given their dimensions and a srcset so
+// that browsers can reserve space for them and pick a suitable size and format.
+func (mr *MarkdownRenderer) renderHTML(source string, images map[string]*Image) (string, []string, error) {
+	src := []byte(source)
+	doc := mr.markdown.Parser().Parse(text.NewReader(src))
+
+	var languages []string
+	var pictures []*ast.Image
+	seen := make(map[string]bool)
+	err := ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := node.(type) {
+		case *ast.FencedCodeBlock:
+			if language := string(n.Language(src)); language != "" && !seen[language] {
+				seen[language] = true
+				languages = append(languages, language)
+			}
+		case *ast.Image:
+			if image, ok := images[string(n.Destination)]; ok && image.Width > 0 {
+				n.SetAttributeString("width", []byte(strconv.Itoa(image.Width)))
+				n.SetAttributeString("height", []byte(strconv.Itoa(image.Height)))
+				pictures = append(pictures, n)
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Variants in the first format go in the srcset of the <img>; any other
+	// formats are offered by a <picture> around it.
+	for _, n := range pictures {
+		image := images[string(n.Destination)]
+		formats := variantFormats(image)
+		if len(formats) == 0 {
+			continue
+		}
+		n.SetAttributeString("srcset", []byte(srcset(image, formats[0], true)))
+		if len(formats) == 1 {
+			continue
+		}
+
+		picture := &pictureNode{}
+		for _, format := range formats[1:] {
+			picture.sources = append(picture.sources, pictureSource{
+				mediaType: imaging.MediaType(format),
+				srcset:    srcset(image, format, false),
+			})
+		}
+		n.Parent().ReplaceChild(n.Parent(), n, picture)
+		picture.AppendChild(picture, n)
+	}
+
+	var buf bytes.Buffer
+	if err := mr.markdown.Renderer().Render(&buf, src, doc); err != nil {
+		return "", nil, err
+	}
+	return buf.String(), languages, nil
+}
+
+// kindPicture is the kind of pictureNode.
+var kindPicture = ast.NewNodeKind("Picture")
+
+// pictureNode wraps an image in a <picture> element that offers its variants
+// in other formats, such as WebP, to browsers that support them.
+type pictureNode struct {
+	ast.BaseInline
+	sources []pictureSource
+}
+
+type pictureSource struct {
+	mediaType string
+	srcset    string
+}
+
+func (n *pictureNode) Kind() ast.NodeKind {
+	return kindPicture
+}
+
+func (n *pictureNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+type pictureRenderer struct{}
+
+func (pictureRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindPicture, renderPicture)
+}
+
+func renderPicture(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		_, _ = w.WriteString("</picture>")
+		return ast.WalkContinue, nil
+	}
+
+	_, _ = w.WriteString("<picture>")
+	for _, s := range node.(*pictureNode).sources {
+		_, _ = fmt.Fprintf(w, `<source type="%s" srcset="%s">`, s.mediaType, html.EscapeString(s.srcset))
+	}
+	return ast.WalkContinue, nil
+}
+
+func renderCodeBlockWrapper(w util.BufWriter, context highlighting.CodeBlockContext, entering bool) {
+	if !entering {
+		_, _ = w.WriteString("</div>\n")
+		return
+	}
+
+	language, ok := context.Language()
+	if !ok {
+		_, _ = w.WriteString(`<div class="highlight">`)
+		return
+	}
+	_, _ = fmt.Fprintf(w, `<div class="highlight" data-language="%s">`, html.EscapeString(string(language)))
+}
+
+// HighlightStylesheet returns the chroma CSS for the given style, matching the
+// class names emitted by MarkdownRenderer.
+func HighlightStylesheet(style string) ([]byte, error) {
+	var buf bytes.Buffer
+	formatter := chromahtml.New(chromahtml.WithClasses(true))
+	if err := formatter.WriteCSS(&buf, styles.Get(style)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
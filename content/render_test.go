@@ -0,0 +1,65 @@
+package content
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tech-arch1tect/mantle/cache"
+	"github.com/tech-arch1tect/mantle/config"
+)
+
+func TestRenderImageVariants(t *testing.T) {
+	cfg := config.NewConfig()
+	renderer := NewMarkdownRenderer(cfg, cache.NewMemoryBuildCache(cfg))
+
+	variant := func(width int, format string) ImageVariant {
+		return ImageVariant{URL: "/media/post/" + VariantName("a.png", width, format), Width: width, Height: width / 2, Format: format}
+	}
+	tests := []struct {
+		name     string
+		variants []ImageVariant
+		want     []string
+		absent   []string
+	}{
+		{
+			name:     "both formats",
+			variants: []ImageVariant{variant(480, config.ImageFormatWebP), variant(480, config.ImageFormatJPEG)},
+			want: []string{
+				`<picture><source type="image/webp" srcset="/media/post/a.png-480w.webp 480w">`,
+				`srcset="/media/post/a.png-480w.jpg 480w, /media/post/a.png 960w"></picture>`,
+			},
+		},
+		{
+			name:     "one format",
+			variants: []ImageVariant{variant(480, config.ImageFormatWebP)},
+			want:     []string{`srcset="/media/post/a.png-480w.webp 480w, /media/post/a.png 960w"`},
+			absent:   []string{"<picture>"},
+		},
+		{
+			name:   "no variants",
+			want:   []string{`width="960" height="480"`},
+			absent: []string{"srcset", "<picture>"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			images := map[string]*Image{
+				"/media/post/a.png": {URL: "/media/post/a.png", Width: 960, Height: 480, Variants: tt.variants},
+			}
+			html, _, err := renderer.renderHTML("![A](/media/post/a.png)", images)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(html, want) {
+					t.Errorf("HTML does not contain %s:\n%s", want, html)
+				}
+			}
+			for _, absent := range tt.absent {
+				if strings.Contains(html, absent) {
+					t.Errorf("HTML contains %s:\n%s", absent, html)
+				}
+			}
+		})
+	}
+}
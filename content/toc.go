@@ -0,0 +1,105 @@
+package content
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+
+	"github.com/tech-arch1tect/mantle/config"
+)
+
+// @Description Table of contents entry for a heading within a post
+type TOCEntry struct {
+	Level    int        `json:"level" example:"2"`
+	Text     string     `json:"text" example:"Installing Go"`
+	ID       string     `json:"id" example:"installing-go"`
+	Children []TOCEntry `json:"children,omitempty"`
+}
+
+type TOCExtractor struct {
+	parser   parser.Parser
+	minDepth int
+	maxDepth int
+}
+
+func NewTOCExtractor(cfg *config.Config) *TOCExtractor {
+	// Parse with the same extensions and heading IDs as MarkdownRenderer so
+	// that anchors in the TOC match the ids in rendered HTML.
+	markdown := goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+	)
+
+	return &TOCExtractor{
+		parser:   markdown.Parser(),
+		minDepth: cfg.TocMinDepth,
+		maxDepth: cfg.TocMaxDepth,
+	}
+}
+
+func (te *TOCExtractor) Extract(markdown string) []TOCEntry {
+	src := []byte(markdown)
+	doc := te.parser.Parse(text.NewReader(src))
+
+	var headings []TOCEntry
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := node.(*ast.Heading)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+
+		if heading.Level >= te.minDepth && heading.Level <= te.maxDepth {
+			entry := TOCEntry{
+				Level: heading.Level,
+				Text:  nodeText(heading, src),
+			}
+			if id, ok := heading.AttributeString("id"); ok {
+				if idBytes, ok := id.([]byte); ok {
+					entry.ID = string(idBytes)
+				}
+			}
+			headings = append(headings, entry)
+		}
+		return ast.WalkSkipChildren, nil
+	})
+
+	return buildTOCTree(headings)
+}
+
+func buildTOCTree(headings []TOCEntry) []TOCEntry {
+	var entries []TOCEntry
+	for len(headings) > 0 {
+		entry := headings[0]
+		headings = headings[1:]
+
+		children := 0
+		for children < len(headings) && headings[children].Level > entry.Level {
+			children++
+		}
+		entry.Children = buildTOCTree(headings[:children])
+		headings = headings[children:]
+
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func nodeText(node ast.Node, src []byte) string {
+	var buf []byte
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		switch n := child.(type) {
+		case *ast.Text:
+			buf = append(buf, n.Segment.Value(src)...)
+			if n.SoftLineBreak() || n.HardLineBreak() {
+				buf = append(buf, ' ')
+			}
+		case *ast.String:
+			buf = append(buf, n.Value...)
+		default:
+			buf = append(buf, nodeText(n, src)...)
+		}
+	}
+	return string(buf)
+}
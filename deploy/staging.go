@@ -0,0 +1,387 @@
+package deploy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tech-arch1tect/mantle/config"
+	"github.com/tech-arch1tect/mantle/internal/fsutil"
+)
+
+const (
+	buildsDirName    = ".builds"
+	stagingDirSuffix = ".staging"
+
+	// CurrentLink is the symlink in OUTPUT_DIR that points at the published
+	// build. Publishing a build replaces it in a single rename.
+	CurrentLink = "current"
+)
+
+// buildEntries are the top-level entries of a build.
+var buildEntries = []string{".mantle", "public_html", "nginx", "Dockerfile", "docker-compose.yml"}
+
+// linkedEntries are linked from OUTPUT_DIR into the published build through
+// CurrentLink, so that a web server configured with the paths of a build
+// without ATOMIC_OUTPUT follows every publish.
+var linkedEntries = []string{"public_html", "nginx"}
+
+var ErrNoPreviousBuild = errors.New("no previous build to roll back to")
+
+// PublishedDir returns the directory holding the published build: OUTPUT_DIR
+// itself, or the build CurrentLink points at when ATOMIC_OUTPUT is enabled.
+func PublishedDir(cfg *config.Config) string {
+	if cfg.AtomicOutput {
+		return filepath.Join(cfg.OutputDir, CurrentLink)
+	}
+	return cfg.OutputDir
+}
+
+// BuildStager builds into a staging directory under OUTPUT_DIR/.builds and
+// publishes it by atomically repointing OUTPUT_DIR/current at it, so a web
+// server never sees a partially written build or a mix of two builds.
+type BuildStager struct {
+	outputDir string
+	buildsDir string
+	keep      int
+	logger    *log.Logger
+	id        string
+}
+
+func NewBuildStager(cfg *config.Config) *BuildStager {
+	return &BuildStager{
+		outputDir: cfg.OutputDir,
+		buildsDir: filepath.Join(cfg.OutputDir, buildsDirName),
+		keep:      cfg.KeepBuilds,
+		logger:    log.New(os.Stdout, "[BuildStager] ", log.LstdFlags),
+	}
+}
+
+// Prepare creates a staging directory seeded with hard links to the published
+// build, so unchanged files do not need to be rewritten, and returns its path.
+// Before the first atomic build, the output of a build without ATOMIC_OUTPUT
+// is used as the seed.
+func (bs *BuildStager) Prepare() (string, error) {
+	if err := bs.removeAbandoned(); err != nil {
+		return "", err
+	}
+
+	bs.id = time.Now().UTC().Format("20060102T150405.000000000")
+	stagingDir := filepath.Join(bs.buildsDir, bs.id+stagingDirSuffix)
+	if err := os.MkdirAll(stagingDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create staging directory: %w", err)
+	}
+
+	seedDir := bs.outputDir
+	if current := bs.current(); current != "" {
+		seedDir = filepath.Join(bs.buildsDir, current)
+	}
+	for _, name := range buildEntries {
+		source := filepath.Join(seedDir, name)
+		if _, err := os.Stat(source); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err := cloneTree(source, filepath.Join(stagingDir, name)); err != nil {
+			return "", fmt.Errorf("failed to seed staging directory from %s: %w", source, err)
+		}
+	}
+
+	bs.logger.Printf("Staging build %s", bs.id)
+	return stagingDir, nil
+}
+
+// Commit publishes the staged build and removes builds beyond the number
+// configured to be kept for rollback.
+func (bs *BuildStager) Commit() error {
+	stagingDir := filepath.Join(bs.buildsDir, bs.id+stagingDirSuffix)
+	if err := os.Rename(stagingDir, filepath.Join(bs.buildsDir, bs.id)); err != nil {
+		return fmt.Errorf("failed to finalise build %s: %w", bs.id, err)
+	}
+
+	if err := bs.activate(bs.id); err != nil {
+		return err
+	}
+	bs.logger.Printf("Published build %s", bs.id)
+
+	if err := bs.linkEntries(); err != nil {
+		return err
+	}
+	return bs.prune()
+}
+
+// Rollback repoints OUTPUT_DIR/current at the build published before the
+// current one and returns its id.
+func (bs *BuildStager) Rollback() (string, error) {
+	builds, err := bs.builds()
+	if err != nil {
+		return "", err
+	}
+
+	current := bs.current()
+	for i := len(builds) - 1; i >= 0; i-- {
+		if current == "" || builds[i] >= current {
+			continue
+		}
+		if err := bs.activate(builds[i]); err != nil {
+			return "", err
+		}
+		if err := bs.linkEntries(); err != nil {
+			return "", err
+		}
+		bs.logger.Printf("Rolled back from build %s to %s", current, builds[i])
+		return builds[i], nil
+	}
+	return "", ErrNoPreviousBuild
+}
+
+// activate points OUTPUT_DIR/current at the build with the given id by
+// renaming a new symlink over it.
+func (bs *BuildStager) activate(id string) error {
+	if err := bs.replaceWithSymlink(CurrentLink, filepath.Join(buildsDirName, id)); err != nil {
+		return fmt.Errorf("failed to publish build %s: %w", id, err)
+	}
+	return nil
+}
+
+// replaceWithSymlink atomically points OUTPUT_DIR/name at target. It fails
+// when name is a directory, which rename cannot replace.
+func (bs *BuildStager) replaceWithSymlink(name, target string) error {
+	tmp := filepath.Join(bs.outputDir, "."+name+".link")
+	_ = os.Remove(tmp)
+	if err := os.Symlink(target, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(bs.outputDir, name)); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// linkEntries maintains the entries of OUTPUT_DIR outside CurrentLink, which
+// only change when they are created or migrated:
+//   - public_html and nginx link to the same entry under CurrentLink;
+//   - docker-compose.yml builds the image from CurrentLink;
+//   - links into .builds made by earlier versions are replaced or removed.
+//
+// Files and directories left over from builds without ATOMIC_OUTPUT are
+// never moved, as a web server may still be reading them; they are reported
+// instead.
+func (bs *BuildStager) linkEntries() error {
+	var leftovers []string
+	for _, name := range buildEntries {
+		path := filepath.Join(bs.outputDir, name)
+		info, err := os.Lstat(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		linked := contains(linkedEntries, name)
+		switch {
+		case err != nil:
+			// Missing: created below.
+		case info.Mode()&os.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			if !isBuildsPath(target) {
+				continue
+			}
+			if !linked && name != "docker-compose.yml" {
+				if err := os.Remove(path); err != nil {
+					return fmt.Errorf("failed to remove outdated link %s: %w", name, err)
+				}
+				continue
+			}
+		case name == "docker-compose.yml" && info.Mode().IsRegular():
+			// Rewritten below; renaming over a file is atomic.
+		default:
+			leftovers = append(leftovers, name)
+			continue
+		}
+
+		switch {
+		case linked:
+			if err := bs.replaceWithSymlink(name, filepath.Join(CurrentLink, name)); err != nil {
+				return fmt.Errorf("failed to link %s: %w", name, err)
+			}
+		case name == "docker-compose.yml":
+			if err := bs.writeCompose(path); err != nil {
+				return fmt.Errorf("failed to write %s: %w", name, err)
+			}
+		}
+	}
+
+	if len(leftovers) > 0 {
+		bs.logger.Printf("Warning: %s in %s are left over from a build without ATOMIC_OUTPUT and are no longer updated; "+
+			"serve %s instead and remove them", strings.Join(leftovers, ", "), bs.outputDir,
+			filepath.Join(bs.outputDir, CurrentLink, "public_html"))
+	}
+	return nil
+}
+
+// writeCompose writes a docker-compose.yml that builds the image from the
+// published build.
+func (bs *BuildStager) writeCompose(path string) error {
+	data := []byte(DockerCompose("./" + CurrentLink))
+	if existing, err := os.ReadFile(path); err == nil && string(existing) == string(data) {
+		return nil
+	}
+	return fsutil.WriteFileAtomic(path, data)
+}
+
+func isBuildsPath(target string) bool {
+	return strings.HasPrefix(filepath.ToSlash(target), buildsDirName+"/")
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// current returns the id of the build OUTPUT_DIR/current points at. Earlier
+// versions linked each entry separately, so .mantle is checked as well.
+func (bs *BuildStager) current() string {
+	target, err := os.Readlink(filepath.Join(bs.outputDir, CurrentLink))
+	if err != nil {
+		target, err = os.Readlink(filepath.Join(bs.outputDir, ".mantle"))
+	}
+	if err != nil {
+		return ""
+	}
+	parts := strings.Split(filepath.ToSlash(target), "/")
+	if len(parts) < 2 || parts[0] != buildsDirName {
+		return ""
+	}
+	return parts[1]
+}
+
+// builds returns the ids of all published builds, oldest first.
+func (bs *BuildStager) builds() ([]string, error) {
+	entries, err := os.ReadDir(bs.buildsDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list builds: %w", err)
+	}
+
+	var builds []string
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasSuffix(entry.Name(), stagingDirSuffix) {
+			builds = append(builds, entry.Name())
+		}
+	}
+	sort.Strings(builds)
+	return builds, nil
+}
+
+func (bs *BuildStager) prune() error {
+	builds, err := bs.builds()
+	if err != nil {
+		return err
+	}
+
+	current := bs.current()
+	kept := 0
+	for i := len(builds) - 1; i >= 0; i-- {
+		if builds[i] == current {
+			continue
+		}
+		if kept < bs.keep {
+			kept++
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(bs.buildsDir, builds[i])); err != nil {
+			return fmt.Errorf("failed to remove old build %s: %w", builds[i], err)
+		}
+		bs.logger.Printf("Removed old build %s", builds[i])
+	}
+	return nil
+}
+
+// removeAbandoned deletes staging directories left behind by failed builds.
+func (bs *BuildStager) removeAbandoned() error {
+	entries, err := os.ReadDir(bs.buildsDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list builds: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), stagingDirSuffix) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(bs.buildsDir, entry.Name())); err != nil {
+			return fmt.Errorf("failed to remove abandoned build %s: %w", entry.Name(), err)
+		}
+		bs.logger.Printf("Removed abandoned staging directory %s", entry.Name())
+	}
+	return nil
+}
+
+// cloneTree recreates source at dest, hard-linking regular files where the
+// filesystem allows it and copying them otherwise. A symlinked source is
+// followed.
+func cloneTree(source, dest string) error {
+	root, err := filepath.EvalSymlinks(source)
+	if err != nil {
+		return err
+	}
+
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, 0755)
+		case d.Type().IsRegular():
+			if err := os.Link(path, target); err == nil {
+				return nil
+			}
+			return copyFile(path, target)
+		default:
+			return nil
+		}
+	})
+}
+
+func copyFile(source, dest string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,174 @@
+package deploy
+
+import (
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tech-arch1tect/mantle/config"
+)
+
+func newTestStager(t *testing.T, outputDir string, keep int) *BuildStager {
+	t.Helper()
+	cfg := config.NewConfig()
+	cfg.OutputDir = outputDir
+	cfg.KeepBuilds = keep
+	bs := NewBuildStager(cfg)
+	bs.logger = log.New(io.Discard, "", 0)
+	return bs
+}
+
+// stageBuild runs a build that writes public_html/index.html with the given
+// contents and returns the id of the build.
+func stageBuild(t *testing.T, bs *BuildStager, contents string, commit bool) string {
+	t.Helper()
+	stagingDir, err := bs.Prepare()
+	if err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	publicDir := filepath.Join(stagingDir, "public_html")
+	if err := os.MkdirAll(publicDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// Builds replace files rather than writing into them, so hard links
+	// shared with earlier builds are never modified.
+	index := filepath.Join(publicDir, "index.html")
+	_ = os.Remove(index)
+	if err := os.WriteFile(index, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if commit {
+		if err := bs.Commit(); err != nil {
+			t.Fatalf("Commit: %v", err)
+		}
+	}
+	return bs.id
+}
+
+func readPublished(t *testing.T, outputDir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(outputDir, name, "index.html"))
+	if err != nil {
+		t.Fatalf("read %s: %v", name, err)
+	}
+	return string(data)
+}
+
+func TestBuildStagerCommitAndRollback(t *testing.T) {
+	outputDir := t.TempDir()
+	bs := newTestStager(t, outputDir, 3)
+
+	first := stageBuild(t, bs, "first", true)
+	second := stageBuild(t, bs, "second", true)
+
+	if target, err := os.Readlink(filepath.Join(outputDir, CurrentLink)); err != nil || target != filepath.Join(buildsDirName, second) {
+		t.Fatalf("current -> %q (%v), want %s", target, err, filepath.Join(buildsDirName, second))
+	}
+	for _, name := range []string{"current/public_html", "public_html"} {
+		if got := readPublished(t, outputDir, name); got != "second" {
+			t.Errorf("%s/index.html = %q, want second", name, got)
+		}
+	}
+	if got := readPublished(t, filepath.Join(outputDir, buildsDirName), first+"/public_html"); got != "first" {
+		t.Errorf("first build was modified by the second: index.html = %q", got)
+	}
+	compose, err := os.ReadFile(filepath.Join(outputDir, "docker-compose.yml"))
+	if err != nil || !strings.Contains(string(compose), "build: ./current") {
+		t.Errorf("docker-compose.yml = %q (%v), want it to build ./current", compose, err)
+	}
+
+	rolledBack, err := bs.Rollback()
+	if err != nil || rolledBack != first {
+		t.Fatalf("Rollback = %q, %v, want %s", rolledBack, err, first)
+	}
+	if got := readPublished(t, outputDir, "public_html"); got != "first" {
+		t.Errorf("public_html/index.html after rollback = %q, want first", got)
+	}
+	if _, err := bs.Rollback(); !errors.Is(err, ErrNoPreviousBuild) {
+		t.Errorf("second Rollback error = %v, want ErrNoPreviousBuild", err)
+	}
+}
+
+func TestBuildStagerUncommittedBuild(t *testing.T) {
+	outputDir := t.TempDir()
+	bs := newTestStager(t, outputDir, 3)
+
+	stageBuild(t, bs, "published", true)
+	abandoned := stageBuild(t, bs, "abandoned", false)
+
+	if got := readPublished(t, outputDir, "public_html"); got != "published" {
+		t.Errorf("public_html/index.html = %q, want the published build", got)
+	}
+
+	stageBuild(t, bs, "next", true)
+	if _, err := os.Stat(filepath.Join(outputDir, buildsDirName, abandoned+stagingDirSuffix)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("abandoned staging directory was not removed: %v", err)
+	}
+}
+
+func TestBuildStagerPrune(t *testing.T) {
+	tests := []struct {
+		keep   int
+		builds int
+		want   int
+	}{
+		{keep: 0, builds: 3, want: 1},
+		{keep: 1, builds: 3, want: 2},
+		{keep: 3, builds: 2, want: 2},
+	}
+	for _, tt := range tests {
+		outputDir := t.TempDir()
+		bs := newTestStager(t, outputDir, tt.keep)
+
+		var last string
+		for i := 0; i < tt.builds; i++ {
+			last = stageBuild(t, bs, "build", true)
+		}
+
+		builds, err := bs.builds()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(builds) != tt.want || builds[len(builds)-1] != last {
+			t.Errorf("KEEP_BUILDS=%d after %d builds: kept %v, want %d ending with %s", tt.keep, tt.builds, builds, tt.want, last)
+		}
+	}
+}
+
+func TestBuildStagerLeavesNonAtomicOutput(t *testing.T) {
+	outputDir := t.TempDir()
+	legacyDir := filepath.Join(outputDir, "public_html")
+	if err := os.MkdirAll(legacyDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"index.html", "about.html"} {
+		if err := os.WriteFile(filepath.Join(legacyDir, name), []byte("legacy"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bs := newTestStager(t, outputDir, 3)
+	id := stageBuild(t, bs, "atomic", false)
+	seeded, err := os.ReadFile(filepath.Join(outputDir, buildsDirName, id+stagingDirSuffix, "public_html", "about.html"))
+	if err != nil || string(seeded) != "legacy" {
+		t.Fatalf("staged about.html = %q (%v), want it seeded from the existing output", seeded, err)
+	}
+	if err := bs.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	info, err := os.Lstat(legacyDir)
+	if err != nil || !info.IsDir() {
+		t.Fatalf("public_html from the non-atomic build was replaced: %v", err)
+	}
+	if got := readPublished(t, outputDir, "public_html"); got != "legacy" {
+		t.Errorf("public_html/index.html = %q, want it left untouched", got)
+	}
+	if got := readPublished(t, outputDir, "current/public_html"); got != "atomic" {
+		t.Errorf("current/public_html/index.html = %q, want atomic", got)
+	}
+}
@@ -1,21 +1,26 @@
-package main
+package deploy
 
 import (
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+
+	"github.com/tech-arch1tect/mantle/config"
+	"github.com/tech-arch1tect/mantle/output"
 )
 
 type WebServerGenerator struct {
-	config *Config
+	config *config.Config
 	logger *log.Logger
+	writer *output.OutputWriter
 }
 
-func NewWebServerGenerator(config *Config) *WebServerGenerator {
+func NewWebServerGenerator(cfg *config.Config, writer *output.OutputWriter) *WebServerGenerator {
 	return &WebServerGenerator{
-		config: config,
+		config: cfg,
 		logger: log.New(os.Stdout, "[WebServerGenerator] ", log.LstdFlags),
+		writer: writer,
 	}
 }
 
@@ -57,23 +62,27 @@ CMD ["nginx", "-g", "daemon off;"]
 }
 
 func (wsg *WebServerGenerator) generateDockerCompose(outputDir string) error {
-	dockerCompose := `services:
+	composePath := filepath.Join(outputDir, "docker-compose.yml")
+	return wsg.writeFile(composePath, DockerCompose("."))
+}
+
+// DockerCompose returns a docker-compose.yml that builds the nginx image from
+// the build directory at context, relative to the file.
+func DockerCompose(context string) string {
+	return fmt.Sprintf(`services:
   api:
-    build: .
+    build: %s
     ports:
       - "8080:80"
     restart: unless-stopped
-`
-
-	composePath := filepath.Join(outputDir, "docker-compose.yml")
-	return wsg.writeFile(composePath, dockerCompose)
+`, context)
 }
 
 func (wsg *WebServerGenerator) generateNginxConfigs(outputDir string) error {
 	nginxDir := filepath.Join(outputDir, "nginx")
 
-	if err := os.MkdirAll(nginxDir, 0755); err != nil {
-		return fmt.Errorf("failed to create nginx directory: %w", err)
+	if err := wsg.writer.MkdirAll(nginxDir); err != nil {
+		return err
 	}
 
 	if err := wsg.generateMainNginxConfig(nginxDir); err != nil {
@@ -119,6 +128,18 @@ server {
         return 404;
     }
     
+    location = /robots.txt {
+        try_files /robots.txt =404;
+    }
+    
+    location = /sitemap.xml {
+        try_files /sitemap.xml =404;
+    }
+    
+    location ~ ^/sitemap-\d+\.xml$ {
+        try_files $uri =404;
+    }
+    
     location = /api/posts/by-page {
         include cors.conf;
         
@@ -139,6 +160,16 @@ server {
         rewrite ^ /api/posts/by-slug/$slug_param.json last;
     }
     
+    location = /api/posts/toc {
+        include cors.conf;
+        
+        if ($slug_param = "") {
+            return 400;
+        }
+        
+        rewrite ^ /api/posts/toc/$slug_param.json last;
+    }
+    
     location = /api/previews/by-page {
         include cors.conf;
         
@@ -169,6 +200,16 @@ server {
         try_files /api/tags/all.json =404;
     }
     
+    location = /api/tags/feed {
+        include cors.conf;
+        
+        if ($tag_name = "") {
+            return 400;
+        }
+        
+        rewrite ^ /api/tags/$tag_name/feed.xml last;
+    }
+    
     location = /api/categories {
         include cors.conf;
         
@@ -179,6 +220,16 @@ server {
         try_files /api/categories/all.json =404;
     }
     
+    location = /api/categories/feed {
+        include cors.conf;
+        
+        if ($category_name = "") {
+            return 400;
+        }
+        
+        rewrite ^ /api/categories/$category_name/feed.xml last;
+    }
+    
     location = /api/related {
         include cors.conf;
         
@@ -189,10 +240,45 @@ server {
         try_files /api/related/all.json =404;
     }
     
+    location = /api/backlinks {
+        include cors.conf;
+        
+        if ($slug_param != "") {
+            rewrite ^ /api/backlinks/$slug_param.json last;
+        }
+        
+        try_files /api/backlinks/all.json =404;
+    }
+    
+    location = /api/series {
+        include cors.conf;
+        
+        if ($series_param != "") {
+            rewrite ^ /api/series/$series_param.json last;
+        }
+        
+        try_files /api/series/all.json =404;
+    }
+    
+    location = /api/archives {
+        include cors.conf;
+        
+        if ($archive_resource != "") {
+            rewrite ^ /api/archives/$archive_resource last;
+        }
+        
+        try_files /api/archives/index.json =404;
+    }
+    
     location /api/ {
         include cors.conf;
         try_files $uri $uri/ =404;
     }
+    
+    location /media/ {
+        include cors.conf;
+        try_files $uri =404;
+    }
 }
 `
 
@@ -215,15 +301,55 @@ map $arg_slug $slug_param {
     default            "";
 }
 
+# Series parameter mapping - ?series=learning-go -> learning-go
+map $arg_series $series_param {
+    ~^([a-z0-9-]+)$    $1;
+    default            "";
+}
+
+# Year parameter mapping - ?year=2024 -> 2024
+map $arg_year $year_param {
+    ~^(\d\d\d\d)$    $1;
+    default          "";
+}
+
+# Month parameter mapping - ?month=3 or ?month=03 -> 03
+map $arg_month $month_param {
+    ~^0?([1-9])$    0$1;
+    ~^(1[0-2])$     $1;
+    default         "";
+}
+
+# Archives mapping - ?year=2024&month=3&page=1 -> 2024/03/by-page/1.json
+map "$year_param/$month_param/$page_param" $archive_resource {
+    ~^(\d+)//$             $1.json;
+    ~^(\d+)//(\d+)$        $1/by-page/$2.json;
+    ~^(\d+)/(\d+)/$        $1/$2.json;
+    ~^(\d+)/(\d+)/(\d+)$   $1/$2/by-page/$3.json;
+    default                "";
+}
+
 # Tags mapping - ?tag=golang -> golang.json
 map $arg_tag $tag_resource {
-    ~^(.+)$     $1.json;
+    ~^([^/]*[^/.][^/]*)$    $1.json;
     default     "";
 }
 
 # Categories mapping - ?category=tech_tutorials -> tech_tutorials.json
 map $arg_category $category_resource {
-    ~^(.+)$     $1.json;
+    ~^([^/]*[^/.][^/]*)$    $1.json;
+    default     "";
+}
+
+# Tag feed mapping - ?tag=golang -> golang
+map $arg_tag $tag_name {
+    ~^([^/]*[^/.][^/]*)$    $1;
+    default     "";
+}
+
+# Category feed mapping - ?category=tech_tutorials -> tech_tutorials
+map $arg_category $category_name {
+    ~^([^/]*[^/.][^/]*)$    $1;
     default     "";
 }
 
@@ -306,12 +432,8 @@ gzip_disable "MSIE [1-6]\.";
 }
 
 func (wsg *WebServerGenerator) writeFile(path, content string) error {
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return fmt.Errorf("failed to create directory for %s: %w", path, err)
-	}
-
-	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
-		return fmt.Errorf("failed to write file %s: %w", path, err)
+	if err := wsg.writer.Write(path, []byte(content)); err != nil {
+		return err
 	}
 
 	wsg.logger.Printf("Generated: %s", filepath.Base(path))
@@ -0,0 +1,53 @@
+package mantle
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+
+	"github.com/tech-arch1tect/mantle/cache"
+	"github.com/tech-arch1tect/mantle/config"
+	"github.com/tech-arch1tect/mantle/output"
+	"github.com/tech-arch1tect/mantle/process"
+	"github.com/tech-arch1tect/mantle/server"
+)
+
+// Handler serves the generated API for processedPosts straight from memory,
+// with the same routes as the generated nginx configuration. Every file is
+// rendered up front; responses carry content-based ETags and honour
+// If-None-Match. Mount it with http.StripPrefix to serve below a sub-path.
+//
+// Nothing is logged. If the API cannot be rendered every request is answered
+// with 500 Internal Server Error; use NewHandler to log and handle the error.
+func Handler(processedPosts process.ProcessedPosts, cfg *config.Config) http.Handler {
+	handler, err := NewHandler(processedPosts, cfg, nil)
+	if err != nil {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		})
+	}
+	return handler
+}
+
+// NewHandler is Handler with a logger for rendering progress and requests,
+// and an error when the API cannot be rendered. A nil logger discards the
+// output.
+func NewHandler(processedPosts process.ProcessedPosts, cfg *config.Config, logger *log.Logger) (http.Handler, error) {
+	if logger == nil {
+		logger = log.New(io.Discard, "", 0)
+	}
+
+	buildCache := cache.NewMemoryBuildCache(cfg)
+	writer := output.NewMemoryOutputWriter(cfg.OutputDir, buildCache)
+	outputProcessor := output.NewOutputProcessor(cfg, buildCache, writer)
+	outputProcessor.SetLogger(logger)
+	if err := outputProcessor.Process(processedPosts); err != nil {
+		return nil, fmt.Errorf("failed to render API: %w", err)
+	}
+
+	router := server.NewRouter(cfg, writer.FS(filepath.Join(cfg.OutputDir, "public_html")))
+	router.SetLogger(logger)
+	return router, nil
+}
@@ -0,0 +1,70 @@
+package mantle
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tech-arch1tect/mantle/cache"
+	"github.com/tech-arch1tect/mantle/config"
+	"github.com/tech-arch1tect/mantle/content"
+	"github.com/tech-arch1tect/mantle/process"
+)
+
+func newTestPosts(t *testing.T) (process.ProcessedPosts, *config.Config) {
+	t.Helper()
+	cfg := config.NewConfig()
+	cfg.OutputDir = t.TempDir()
+	cfg.GenerateSwagger = false
+	posts := process.NewPostProcessor(cfg, cache.NewMemoryBuildCache(cfg)).Process([]content.Post{
+		{FrontMatter: content.FrontMatter{Title: "Hello", Slug: "hello", Author: "A", Date: "2024-01-01"}},
+	})
+	return posts, cfg
+}
+
+func TestHandler(t *testing.T) {
+	posts, cfg := newTestPosts(t)
+
+	recorder := httptest.NewRecorder()
+	Handler(posts, cfg).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/posts/by-slug?slug=hello", nil))
+	if recorder.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", recorder.Code)
+	}
+}
+
+func TestNewHandlerLogger(t *testing.T) {
+	posts, cfg := newTestPosts(t)
+
+	var logs bytes.Buffer
+	tests := []struct {
+		name    string
+		logger  *log.Logger
+		wantLog string
+	}{
+		{"discarded by default", nil, ""},
+		{"custom logger", log.New(&logs, "", 0), "GET /api/posts/by-slug?slug=hello 200"},
+	}
+	for _, tt := range tests {
+		logs.Reset()
+		handler, err := NewHandler(posts, cfg, tt.logger)
+		if err != nil {
+			t.Fatalf("%s: NewHandler: %v", tt.name, err)
+		}
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/posts/by-slug?slug=hello", nil))
+		if recorder.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, want 200", tt.name, recorder.Code)
+		}
+
+		if tt.wantLog == "" && logs.Len() > 0 {
+			t.Errorf("%s: logged %q, want nothing", tt.name, logs.String())
+		}
+		if !strings.Contains(logs.String(), tt.wantLog) {
+			t.Errorf("%s: log %q does not contain %q", tt.name, logs.String(), tt.wantLog)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Package imaging decodes, resizes and encodes images using pure-Go codecs.
+package imaging
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+
+	"github.com/tech-arch1tect/mantle/config"
+)
+
+// placeholderWidth is the width of the placeholder returned by Placeholder.
+// Browsers scale it up, which blurs it.
+const placeholderWidth = 16
+
+var extensions = map[string]bool{
+	".png":  true,
+	".jpg":  true,
+	".jpeg": true,
+	".gif":  true,
+	".webp": true,
+}
+
+// IsImage reports whether name has the extension of an image format that can
+// be decoded.
+func IsImage(name string) bool {
+	return extensions[strings.ToLower(path.Ext(name))]
+}
+
+// Extension returns the file extension used for format.
+func Extension(format string) string {
+	if format == config.ImageFormatJPEG {
+		return ".jpg"
+	}
+	return "." + format
+}
+
+// MediaType returns the MIME type of format.
+func MediaType(format string) string {
+	return "image/" + format
+}
+
+func Decode(data []byte) (image.Image, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return img, nil
+}
+
+// ScaledHeight returns the height of an image of the given size scaled to
+// width, keeping its aspect ratio.
+func ScaledHeight(width, height, scaledWidth int) int {
+	if width == 0 {
+		return 0
+	}
+	scaled := (height*scaledWidth + width/2) / width
+	if scaled < 1 {
+		return 1
+	}
+	return scaled
+}
+
+// Resize scales img to width, keeping its aspect ratio.
+func Resize(img image.Image, width int) image.Image {
+	bounds := img.Bounds()
+	height := ScaledHeight(bounds.Dx(), bounds.Dy(), width)
+	resized := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(resized, resized.Bounds(), img, bounds, draw.Over, nil)
+	return resized
+}
+
+// Encode writes img to w as format. WebP is always lossless, so quality only
+// applies to JPEG. JPEG has no transparency; transparent areas become white.
+func Encode(w io.Writer, img image.Image, format string, quality int) error {
+	switch format {
+	case config.ImageFormatWebP:
+		return nativewebp.Encode(w, img, nil)
+	case config.ImageFormatJPEG:
+		return jpeg.Encode(w, flatten(img), &jpeg.Options{Quality: quality})
+	default:
+		return fmt.Errorf("unsupported image format %q", format)
+	}
+}
+
+// Placeholder returns a tiny JPEG version of img as a data URI, to be shown
+// blurred while the image loads.
+func Placeholder(img image.Image) (string, error) {
+	width := placeholderWidth
+	if bounds := img.Bounds(); bounds.Dx() < width {
+		width = bounds.Dx()
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, flatten(Resize(img, width)), &jpeg.Options{Quality: 50}); err != nil {
+		return "", fmt.Errorf("failed to encode placeholder: %w", err)
+	}
+	return "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+func flatten(img image.Image) image.Image {
+	bounds := img.Bounds()
+	flat := image.NewRGBA(bounds)
+	draw.Draw(flat, bounds, image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, bounds, img, bounds.Min, draw.Over)
+	return flat
+}
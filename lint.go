@@ -0,0 +1,45 @@
+package mantle
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/tech-arch1tect/mantle/cache"
+	"github.com/tech-arch1tect/mantle/config"
+	"github.com/tech-arch1tect/mantle/content"
+	"github.com/tech-arch1tect/mantle/lint"
+)
+
+// Lint loads the posts in cfg.ContentDir, including drafts and posts dated in
+// the future, and checks them for content problems. Nothing is written.
+func Lint(ctx context.Context, cfg *config.Config, options lint.Options) ([]lint.Finding, error) {
+	lintCfg := *cfg
+	lintCfg.BuildDrafts = true
+	lintCfg.BuildFuture = true
+
+	publishFilter, err := content.NewPublishFilter(&lintCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine build clock: %w", err)
+	}
+
+	loader := content.NewPostLoader(&lintCfg, publishFilter, cache.NewMemoryBuildCache(&lintCfg))
+	loader.SetLogger(log.New(io.Discard, "", 0))
+	posts, err := loader.LoadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load posts: %w", err)
+	}
+
+	if cfg.CategoryFromPath {
+		options.CategoryDirs, err = loader.CategoryDirs()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list category directories: %w", err)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return lint.NewLinter(options).Lint(posts, loader.Issues()), nil
+}
@@ -0,0 +1,196 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatSARIF = "sarif"
+)
+
+// Counts tallies findings by severity.
+type Counts struct {
+	Errors   int `json:"errors"`
+	Warnings int `json:"warnings"`
+	Notes    int `json:"notes"`
+}
+
+func Count(findings []Finding) Counts {
+	var counts Counts
+	for _, finding := range findings {
+		switch finding.Severity {
+		case SeverityError:
+			counts.Errors++
+		case SeverityWarning:
+			counts.Warnings++
+		default:
+			counts.Notes++
+		}
+	}
+	return counts
+}
+
+// Write formats findings as text, JSON or SARIF. Paths in text output are
+// prefixed with baseDir so that they resolve from the repository root; SARIF
+// output gives them relative to the base URI contentDirBaseID, which resolves
+// to baseDir, and JSON keeps them relative to CONTENT_DIR.
+func Write(w io.Writer, format string, findings []Finding, baseDir string) error {
+	switch format {
+	case FormatText:
+		return writeText(w, findings, baseDir)
+	case FormatJSON:
+		return writeJSON(w, struct {
+			Counts
+			Findings []Finding `json:"findings"`
+		}{Count(findings), append([]Finding{}, findings...)})
+	case FormatSARIF:
+		return writeJSON(w, newSARIFLog(findings, baseDir))
+	default:
+		return fmt.Errorf("unknown format %q, expected %q, %q or %q", format, FormatText, FormatJSON, FormatSARIF)
+	}
+}
+
+func writeText(w io.Writer, findings []Finding, baseDir string) error {
+	for _, finding := range findings {
+		finding.File = path.Join(baseDir, finding.File)
+		if _, err := fmt.Fprintln(w, finding); err != nil {
+			return err
+		}
+	}
+	counts := Count(findings)
+	_, err := fmt.Fprintf(w, "%d error(s), %d warning(s), %d note(s)\n", counts.Errors, counts.Warnings, counts.Notes)
+	return err
+}
+
+func writeJSON(w io.Writer, data interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+// contentDirBaseID names CONTENT_DIR in the originalUriBaseIds of SARIF
+// output. A relative CONTENT_DIR is resolved against %SRCROOT%, the root of
+// the checkout being analysed.
+const contentDirBaseID = "CONTENTDIR"
+
+// The subset of SARIF 2.1.0 needed to annotate files in code review tools.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                        `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds"`
+	Results            []sarifResult                    `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level Severity `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     Severity        `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+func newSARIFLog(findings []Finding, baseDir string) sarifLog {
+	rules := make([]sarifRule, 0, len(Rules))
+	for _, rule := range Rules {
+		rules = append(rules, sarifRule{
+			ID:                   rule.ID,
+			ShortDescription:     sarifMessage{Text: rule.Description},
+			DefaultConfiguration: sarifConfiguration{Level: rule.Severity},
+		})
+	}
+
+	results := make([]sarifResult, 0, len(findings))
+	for _, finding := range findings {
+		location := sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{
+				URI:       (&url.URL{Path: finding.File}).String(),
+				URIBaseID: contentDirBaseID,
+			},
+		}
+		if finding.Line > 0 {
+			location.Region = &sarifRegion{StartLine: finding.Line}
+		}
+		results = append(results, sarifResult{
+			RuleID:    finding.Rule,
+			Level:     finding.Severity,
+			Message:   sarifMessage{Text: finding.Message},
+			Locations: []sarifLocation{{PhysicalLocation: location}},
+		})
+	}
+
+	return sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool:               sarifTool{Driver: sarifDriver{Name: "mantle", Rules: rules}},
+			OriginalURIBaseIDs: map[string]sarifArtifactLocation{contentDirBaseID: sarifBaseLocation(baseDir)},
+			Results:            results,
+		}},
+	}
+}
+
+// sarifBaseLocation returns the location of baseDir as a SARIF base URI,
+// which must end in a slash.
+func sarifBaseLocation(baseDir string) sarifArtifactLocation {
+	dir := path.Clean(filepath.ToSlash(baseDir)) + "/"
+	if !filepath.IsAbs(baseDir) {
+		return sarifArtifactLocation{URI: (&url.URL{Path: dir}).String(), URIBaseID: "%SRCROOT%"}
+	}
+	if !strings.HasPrefix(dir, "/") {
+		dir = "/" + dir
+	}
+	return sarifArtifactLocation{URI: (&url.URL{Scheme: "file", Path: dir}).String()}
+}
@@ -0,0 +1,43 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestSARIFLocations(t *testing.T) {
+	findings := []Finding{{Rule: RuleImageAlt, Severity: SeverityWarning, File: "guides/my post.md", Line: 3, Message: "no alt"}}
+
+	tests := []struct {
+		baseDir   string
+		wantBase  string
+		wantRoot  string
+		wantFound string
+	}{
+		{"content", "content/", "%SRCROOT%", "guides/my%20post.md"},
+		{"./site/posts/", "site/posts/", "%SRCROOT%", "guides/my%20post.md"},
+		{".", "./", "%SRCROOT%", "guides/my%20post.md"},
+		{"/srv/blog/content", "file:///srv/blog/content/", "", "guides/my%20post.md"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := Write(&buf, FormatSARIF, findings, tt.baseDir); err != nil {
+			t.Fatal(err)
+		}
+		var log sarifLog
+		if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+			t.Fatal(err)
+		}
+
+		run := log.Runs[0]
+		base := run.OriginalURIBaseIDs[contentDirBaseID]
+		if base.URI != tt.wantBase || base.URIBaseID != tt.wantRoot {
+			t.Errorf("baseDir %q: %s = %+v, want uri %q based on %q", tt.baseDir, contentDirBaseID, base, tt.wantBase, tt.wantRoot)
+		}
+		location := run.Results[0].Locations[0].PhysicalLocation.ArtifactLocation
+		if location.URI != tt.wantFound || location.URIBaseID != contentDirBaseID {
+			t.Errorf("baseDir %q: artifact location = %+v, want %q relative to %s", tt.baseDir, location, tt.wantFound, contentDirBaseID)
+		}
+	}
+}
@@ -0,0 +1,345 @@
+// Package lint checks loaded posts for content problems that do not stop a
+// build but are worth fixing before content is merged.
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+
+	"github.com/tech-arch1tect/mantle/content"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityNote    Severity = "note"
+)
+
+// Rule describes one kind of Finding.
+type Rule struct {
+	ID          string
+	Severity    Severity
+	Description string
+}
+
+// Rules lists every rule the linter reports, starting with the issues
+// reported by content.PostLoader.
+var Rules = []Rule{
+	{content.IssueLoadError, SeverityError, "The file could not be read"},
+	{content.IssueInvalidFrontMatter, SeverityError, "The frontmatter is missing or is not valid YAML"},
+	{content.IssueInvalidDate, SeverityError, "A date does not match DATE_FORMAT"},
+	{content.IssueMissingField, SeverityWarning, "The title, author or date is empty"},
+	{content.IssueSlugCollision, SeverityWarning, "The slug is already used by another post and was given a numeric suffix"},
+	{content.IssueBrokenLink, SeverityError, "A link to another post points to a missing, unpublished or draft post"},
+	{content.IssueInvalidImage, SeverityError, "An image in a page bundle could not be decoded"},
+	{RuleDuplicateTitle, SeverityWarning, "Another post has the same title"},
+	{RuleTagCase, SeverityWarning, "A tag differs from another tag only by case"},
+	{RuleUnusedCategory, SeverityWarning, "A category directory contains no posts"},
+	{RuleSinglePostCategory, SeverityNote, "Only one post uses the category, which often means a typo"},
+	{RuleImageAlt, SeverityWarning, "An image has no alt text"},
+	{RuleLongExcerpt, SeverityWarning, "The excerpt is longer than the configured maximum"},
+}
+
+const (
+	RuleDuplicateTitle     = "duplicate-title"
+	RuleTagCase            = "tag-case"
+	RuleUnusedCategory     = "unused-category"
+	RuleSinglePostCategory = "single-post-category"
+	RuleBrokenLink         = content.IssueBrokenLink
+	RuleImageAlt           = "image-alt"
+	RuleLongExcerpt        = "long-excerpt"
+)
+
+// Finding is a single problem found in a content file. File is relative to
+// CONTENT_DIR and Line is 1-based, or 0 when the problem has no position.
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	File     string   `json:"file"`
+	Line     int      `json:"line,omitempty"`
+	Message  string   `json:"message"`
+}
+
+func (f Finding) String() string {
+	location := f.File
+	if f.Line > 0 {
+		location = fmt.Sprintf("%s:%d", f.File, f.Line)
+	}
+	return fmt.Sprintf("%s: %s [%s] %s", location, f.Severity, f.Rule, f.Message)
+}
+
+type Options struct {
+	// MaxExcerptLength is the longest excerpt, in characters, that is not
+	// reported by the long-excerpt rule.
+	MaxExcerptLength int
+	// CategoryDirs lists the directories, relative to CONTENT_DIR, that posts
+	// take their category from under CATEGORY_FROM_PATH. The unused-category
+	// rule reports those that no post's category is in; it is skipped when
+	// the list is empty.
+	CategoryDirs []string
+}
+
+func DefaultOptions() Options {
+	return Options{MaxExcerptLength: 300}
+}
+
+type Linter struct {
+	options  Options
+	parser   parser.Parser
+	severity map[string]Severity
+}
+
+func NewLinter(options Options) *Linter {
+	severity := make(map[string]Severity, len(Rules))
+	for _, rule := range Rules {
+		severity[rule.ID] = rule.Severity
+	}
+
+	return &Linter{
+		options:  options,
+		parser:   goldmark.New(goldmark.WithExtensions(extension.GFM)).Parser(),
+		severity: severity,
+	}
+}
+
+// Lint checks posts, as returned by content.PostLoader.LoadAll, together with
+// the issues the loader reported, and returns the findings sorted by file.
+func (l *Linter) Lint(posts []content.Post, issues []content.Issue) []Finding {
+	var findings []Finding
+	for _, issue := range issues {
+		findings = append(findings, l.finding(issue.Kind, issue.File, issue.Line, "%s", issue.Message))
+	}
+
+	findings = append(findings, l.checkTitles(posts)...)
+	findings = append(findings, l.checkTags(posts)...)
+	findings = append(findings, l.checkUnusedCategories(posts)...)
+	findings = append(findings, l.checkCategories(posts)...)
+	findings = append(findings, l.checkExcerpts(posts)...)
+
+	drafts := make(map[string]string)
+	for _, post := range posts {
+		if post.FrontMatter.Draft {
+			drafts[post.Permalink] = post.SourcePath
+		}
+	}
+	for _, post := range posts {
+		findings = append(findings, l.checkMarkdown(post, drafts)...)
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].File != findings[j].File {
+			return findings[i].File < findings[j].File
+		}
+		return findings[i].Line < findings[j].Line
+	})
+	return findings
+}
+
+func (l *Linter) finding(rule, file string, line int, format string, args ...interface{}) Finding {
+	return Finding{
+		Rule:     rule,
+		Severity: l.severity[rule],
+		File:     file,
+		Line:     line,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+func (l *Linter) checkTitles(posts []content.Post) []Finding {
+	var findings []Finding
+	firstUse := make(map[string]string)
+	for _, post := range posts {
+		title := strings.ToLower(strings.TrimSpace(post.FrontMatter.Title))
+		if title == "" {
+			continue
+		}
+		if other, ok := firstUse[title]; ok {
+			findings = append(findings, l.finding(RuleDuplicateTitle, post.SourcePath, 0,
+				"title %q is also used by %s", post.FrontMatter.Title, other))
+			continue
+		}
+		firstUse[title] = post.SourcePath
+	}
+	return findings
+}
+
+// checkTags reports every use of a tag spelled differently from the most
+// common spelling of the same tag.
+func (l *Linter) checkTags(posts []content.Post) []Finding {
+	spellings := make(map[string]map[string]int)
+	for _, post := range posts {
+		for _, tag := range post.FrontMatter.Tags {
+			key := strings.ToLower(tag)
+			if spellings[key] == nil {
+				spellings[key] = make(map[string]int)
+			}
+			spellings[key][tag]++
+		}
+	}
+
+	preferred := make(map[string]string)
+	for key, counts := range spellings {
+		best := ""
+		for spelling, count := range counts {
+			if best == "" || count > counts[best] || (count == counts[best] && spelling < best) {
+				best = spelling
+			}
+		}
+		preferred[key] = best
+	}
+
+	var findings []Finding
+	for _, post := range posts {
+		for _, tag := range post.FrontMatter.Tags {
+			key := strings.ToLower(tag)
+			if best := preferred[key]; tag != best {
+				findings = append(findings, l.finding(RuleTagCase, post.SourcePath, 0,
+					"tag %q differs only by case from %q, used by %d post(s)", tag, best, spellings[key][best]))
+			}
+		}
+	}
+	return findings
+}
+
+// checkUnusedCategories reports category directories that no post is
+// categorised under, such as a directory whose posts were all moved or all
+// set their own category.
+func (l *Linter) checkUnusedCategories(posts []content.Post) []Finding {
+	used := make(map[string]bool)
+	for _, post := range posts {
+		parts := strings.Split(post.FrontMatter.Category, "/")
+		for i := range parts {
+			used[strings.Join(parts[:i+1], "/")] = true
+		}
+	}
+
+	var findings []Finding
+	for _, dir := range l.options.CategoryDirs {
+		if !used[dir] {
+			findings = append(findings, l.finding(RuleUnusedCategory, dir, 0,
+				"category directory %q contains no posts", dir))
+		}
+	}
+	return findings
+}
+
+// checkCategories reports categories used by a single post, which are often
+// a misspelling of another category.
+func (l *Linter) checkCategories(posts []content.Post) []Finding {
+	users := make(map[string][]string)
+	for _, post := range posts {
+		if category := post.FrontMatter.Category; category != "" {
+			users[category] = append(users[category], post.SourcePath)
+		}
+	}
+
+	categories := make([]string, 0, len(users))
+	for category := range users {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	var findings []Finding
+	for _, category := range categories {
+		if files := users[category]; len(files) == 1 {
+			findings = append(findings, l.finding(RuleSinglePostCategory, files[0], 0,
+				"category %q is not used by any other post", category))
+		}
+	}
+	return findings
+}
+
+func (l *Linter) checkExcerpts(posts []content.Post) []Finding {
+	if l.options.MaxExcerptLength <= 0 {
+		return nil
+	}
+
+	var findings []Finding
+	for _, post := range posts {
+		if length := len([]rune(post.Excerpt)); length > l.options.MaxExcerptLength {
+			findings = append(findings, l.finding(RuleLongExcerpt, post.SourcePath, 0,
+				"excerpt is %d characters long, more than %d; set excerpt in the frontmatter or move <!--more--> up",
+				length, l.options.MaxExcerptLength))
+		}
+	}
+	return findings
+}
+
+// checkMarkdown reports links from published posts to drafts and images
+// without alt text in the body of post. Links to other posts have already been
+// resolved to permalinks by the loader; drafts maps the permalink of every
+// draft to its source path.
+func (l *Linter) checkMarkdown(post content.Post, drafts map[string]string) []Finding {
+	source := []byte(post.Markdown)
+	doc := l.parser.Parse(text.NewReader(source))
+
+	var findings []Finding
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch n := node.(type) {
+		case *ast.Link:
+			if post.FrontMatter.Draft {
+				break
+			}
+			permalink, _, _ := strings.Cut(string(n.Destination), "#")
+			if target, ok := drafts[permalink]; ok {
+				findings = append(findings, l.finding(RuleBrokenLink, post.SourcePath, post.BodyOffset+lineOf(node, source),
+					"link to %q points to %s, which is a draft", string(n.Destination), target))
+			}
+		case *ast.Image:
+			if strings.TrimSpace(nodeText(n, source)) == "" {
+				findings = append(findings, l.finding(RuleImageAlt, post.SourcePath, post.BodyOffset+lineOf(node, source),
+					"image %q has no alt text", string(n.Destination)))
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	return findings
+}
+
+// lineOf returns the 1-based line of node within source, using the first text
+// below it or, failing that, the block that contains it.
+func lineOf(node ast.Node, source []byte) int {
+	start := -1
+	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if t, ok := n.(*ast.Text); ok && entering {
+			start = t.Segment.Start
+			return ast.WalkStop, nil
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for n := node; start < 0 && n != nil; n = n.Parent() {
+		if n.Type() == ast.TypeBlock && n.Lines().Len() > 0 {
+			start = n.Lines().At(0).Start
+		}
+	}
+	if start < 0 {
+		return 0
+	}
+	return strings.Count(string(source[:start]), "\n") + 1
+}
+
+func nodeText(node ast.Node, source []byte) string {
+	var b strings.Builder
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		if t, ok := child.(*ast.Text); ok {
+			b.Write(t.Segment.Value(source))
+			continue
+		}
+		b.WriteString(nodeText(child, source))
+	}
+	return b.String()
+}
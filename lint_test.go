@@ -0,0 +1,71 @@
+package mantle
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/tech-arch1tect/mantle/config"
+	"github.com/tech-arch1tect/mantle/lint"
+)
+
+func TestLintUnusedCategories(t *testing.T) {
+	contentDir := t.TempDir()
+	files := map[string]string{
+		"guides/go/basics.md":          "title: Basics",
+		"guides/go/bundle/index.md":    "title: Bundle",
+		"guides/go/bundle/img/a.png":   "",
+		"notes/moved.md":               "title: Moved\ncategory: guides",
+		"empty/.keep":                  "",
+		"drafts-old/assets/README.txt": "",
+		".git/HEAD":                    "",
+		"archive/old.md":               "title: Old",
+	}
+	for name, frontMatter := range files {
+		path := filepath.Join(contentDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		contents := frontMatter
+		if filepath.Ext(name) == ".md" {
+			contents = "---\n" + frontMatter + "\nauthor: A\ndate: 01/01/2024\n---\nBody\n"
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.NewConfig()
+	cfg.ContentDir = contentDir
+	cfg.DateFormat = "02/01/2006"
+	cfg.ContentExclude = "archive"
+	cfg.CategoryFromPath = true
+
+	findings, err := Lint(context.Background(), cfg, lint.DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, finding := range findings {
+		if finding.Rule == lint.RuleUnusedCategory {
+			got = append(got, finding.File)
+		}
+	}
+	want := []string{"drafts-old", "drafts-old/assets", "empty", "notes"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unused categories = %v, want %v", got, want)
+	}
+
+	cfg.CategoryFromPath = false
+	findings, err = Lint(context.Background(), cfg, lint.DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, finding := range findings {
+		if finding.Rule == lint.RuleUnusedCategory {
+			t.Errorf("without CATEGORY_FROM_PATH: reported %s", finding)
+		}
+	}
+}
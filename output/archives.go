@@ -0,0 +1,95 @@
+package output
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/tech-arch1tect/mantle/content"
+)
+
+// @Description Number of posts published in a month
+type ArchiveMonth struct {
+	Month int `json:"month" example:"3"`
+	Count int `json:"count" example:"4"`
+}
+
+// @Description Number of posts published in a year, with the months that have posts
+type ArchiveYear struct {
+	Year   int            `json:"year" example:"2024"`
+	Count  int            `json:"count" example:"12"`
+	Months []ArchiveMonth `json:"months"`
+}
+
+// @Description Years and months with published posts, newest first
+type ArchiveIndex []ArchiveYear
+
+// saveArchives writes an index of the years and months with posts, and the
+// paginated previews of the posts of each year and month. posts must be sorted
+// newest first; posts whose date could not be parsed are left out.
+func (op *OutputProcessor) saveArchives(posts []content.Post) error {
+	index := ArchiveIndex{}
+	byYear := make(map[int][]content.PostPreview)
+	byMonth := make(map[int]map[int][]content.PostPreview)
+
+	for _, post := range posts {
+		date, ok := op.dates[post.FrontMatter.Slug]
+		if !ok {
+			continue
+		}
+		year, month := date.Year(), int(date.Month())
+
+		if _, ok := byYear[year]; !ok {
+			index = append(index, ArchiveYear{Year: year, Months: []ArchiveMonth{}})
+			byMonth[year] = make(map[int][]content.PostPreview)
+		}
+		archive := &index[len(index)-1]
+		if _, ok := byMonth[year][month]; !ok {
+			archive.Months = append(archive.Months, ArchiveMonth{Month: month})
+		}
+		archive.Count++
+		archive.Months[len(archive.Months)-1].Count++
+
+		preview := content.NewPostPreview(post)
+		byYear[year] = append(byYear[year], preview)
+		byMonth[year][month] = append(byMonth[year][month], preview)
+	}
+
+	archivesDir := filepath.Join(op.config.OutputDir, "public_html", "api", "archives")
+	if err := op.saveJSON(filepath.Join(archivesDir, "index.json"), index); err != nil {
+		return fmt.Errorf("failed to save archive index: %w", err)
+	}
+
+	for _, archive := range index {
+		yearDir := filepath.Join(archivesDir, fmt.Sprintf("%04d", archive.Year))
+		if err := op.saveArchivePages(yearDir, byYear[archive.Year]); err != nil {
+			return fmt.Errorf("failed to save archive %04d: %w", archive.Year, err)
+		}
+
+		for _, month := range archive.Months {
+			monthDir := filepath.Join(yearDir, fmt.Sprintf("%02d", month.Month))
+			if err := op.saveArchivePages(monthDir, byMonth[archive.Year][month.Month]); err != nil {
+				return fmt.Errorf("failed to save archive %04d/%02d: %w", archive.Year, month.Month, err)
+			}
+		}
+	}
+
+	op.logger.Printf("Saved archives for %d year(s)", len(index))
+	return nil
+}
+
+// saveArchivePages writes the first page of previews to {dir}.json and every
+// page to {dir}/by-page/{page}.json.
+func (op *OutputProcessor) saveArchivePages(dir string, previews []content.PostPreview) error {
+	pages := op.paginatePreviews(previews)
+	if err := op.saveJSON(dir+".json", pages[0]); err != nil {
+		return err
+	}
+
+	for page, paginated := range pages {
+		pagePath := filepath.Join(dir, "by-page", fmt.Sprintf("%d.json", page))
+		if err := op.saveJSON(pagePath, paginated); err != nil {
+			return fmt.Errorf("failed to save page %d: %w", page, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,360 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tech-arch1tect/mantle/config"
+	"github.com/tech-arch1tect/mantle/content"
+	"github.com/tech-arch1tect/mantle/process"
+)
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	DCNS    string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	Description string   `xml:"description"`
+	PubDate     string   `xml:"pubDate,omitempty"`
+	Creator     string   `xml:"dc:creator,omitempty"`
+	Categories  []string `xml:"category"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	ID       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Links      []atomLink     `xml:"link"`
+	Published  string         `xml:"published,omitempty"`
+	Updated    string         `xml:"updated"`
+	Author     *atomPerson    `xml:"author,omitempty"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Content    *atomText      `xml:"content,omitempty"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description,omitempty"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url"`
+	Title         string           `json:"title"`
+	ContentHTML   string           `json:"content_html,omitempty"`
+	Summary       string           `json:"summary,omitempty"`
+	DatePublished string           `json:"date_published,omitempty"`
+	Authors       []jsonFeedAuthor `json:"authors,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+func (op *OutputProcessor) saveFeeds(posts []content.Post, processedPosts process.ProcessedPosts) error {
+	apiDir := filepath.Join(op.config.OutputDir, "public_html", "api")
+	items := op.feedItems(posts)
+
+	rss := op.buildRSSFeed(op.config.SiteName, op.config.SiteDescription, op.apiURL("feed.xml"), items)
+	if err := op.saveXML(filepath.Join(apiDir, "feed.xml"), rss); err != nil {
+		return fmt.Errorf("failed to save RSS feed: %w", err)
+	}
+
+	atom := op.buildAtomFeed(op.config.SiteName, op.config.SiteDescription, op.apiURL("atom.xml"), items)
+	if err := op.saveXML(filepath.Join(apiDir, "atom.xml"), atom); err != nil {
+		return fmt.Errorf("failed to save Atom feed: %w", err)
+	}
+
+	feed := op.buildJSONFeed(op.config.SiteName, op.config.SiteDescription, op.apiURL("feed.json"), items)
+	if err := op.saveJSON(filepath.Join(apiDir, "feed.json"), feed); err != nil {
+		return fmt.Errorf("failed to save JSON feed: %w", err)
+	}
+
+	op.logger.Printf("Saved feeds with %d item(s)", len(items))
+
+	if err := op.saveTagFeeds(processedPosts.Tags, posts); err != nil {
+		return err
+	}
+
+	return op.saveCategoryFeeds(processedPosts.Categories, posts)
+}
+
+func (op *OutputProcessor) saveTagFeeds(tags map[string][]string, sortedPosts []content.Post) error {
+	for tag, postSlugs := range tags {
+		posts := op.filterPostsBySlug(sortedPosts, postSlugs)
+		title := fmt.Sprintf("%s - %s", op.config.SiteName, tag)
+		description := fmt.Sprintf("Posts tagged %s", tag)
+		safeFilename := taxonomyFileName(tag)
+		resource := fmt.Sprintf("tags/%s/feed.xml", url.PathEscape(safeFilename))
+
+		rss := op.buildRSSFeed(title, description, op.apiURL(resource), op.feedItems(posts))
+		feedPath := filepath.Join(op.config.OutputDir, "public_html", "api", "tags", safeFilename, "feed.xml")
+		if err := op.saveXML(feedPath, rss); err != nil {
+			return fmt.Errorf("failed to save feed for tag %s: %w", tag, err)
+		}
+	}
+
+	op.logger.Printf("Saved feeds for %d tags", len(tags))
+	return nil
+}
+
+func (op *OutputProcessor) saveCategoryFeeds(categories map[string]process.CategoryInfo, sortedPosts []content.Post) error {
+	for categoryPath, info := range categories {
+		safeFilename := taxonomyFileName(categoryPath)
+		posts := op.filterPostsBySlug(sortedPosts, info.PostSlugs)
+		title := fmt.Sprintf("%s - %s", op.config.SiteName, categoryPath)
+		description := fmt.Sprintf("Posts in category %s", categoryPath)
+		resource := fmt.Sprintf("categories/%s/feed.xml", url.PathEscape(safeFilename))
+
+		rss := op.buildRSSFeed(title, description, op.apiURL(resource), op.feedItems(posts))
+		feedPath := filepath.Join(op.config.OutputDir, "public_html", "api", "categories", safeFilename, "feed.xml")
+		if err := op.saveXML(feedPath, rss); err != nil {
+			return fmt.Errorf("failed to save feed for category %s: %w", categoryPath, err)
+		}
+	}
+
+	op.logger.Printf("Saved feeds for %d categories", len(categories))
+	return nil
+}
+
+func (op *OutputProcessor) filterPostsBySlug(sortedPosts []content.Post, slugs []string) []content.Post {
+	wanted := make(map[string]bool, len(slugs))
+	for _, slug := range slugs {
+		wanted[slug] = true
+	}
+
+	var posts []content.Post
+	for _, post := range sortedPosts {
+		if wanted[post.FrontMatter.Slug] {
+			posts = append(posts, post)
+		}
+	}
+	return posts
+}
+
+func (op *OutputProcessor) feedItems(posts []content.Post) []content.Post {
+	if len(posts) > op.config.FeedLimit {
+		return posts[:op.config.FeedLimit]
+	}
+	return posts
+}
+
+func (op *OutputProcessor) buildRSSFeed(title, description, selfURL string, posts []content.Post) rssFeed {
+	feed := rssFeed{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		DCNS:    "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:       title,
+			Link:        op.siteURL(),
+			Description: description,
+			AtomLink:    atomLink{Href: selfURL, Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+
+	if len(posts) > 0 {
+		if date, ok := op.postDate(posts[0]); ok {
+			feed.Channel.LastBuildDate = date.Format(time.RFC1123Z)
+		}
+	}
+
+	for _, post := range posts {
+		link := op.postURL(post)
+		item := rssItem{
+			Title:       post.FrontMatter.Title,
+			Link:        link,
+			GUID:        rssGUID{IsPermaLink: true, Value: link},
+			Description: op.feedContent(post),
+			Creator:     post.FrontMatter.Author,
+			Categories:  post.FrontMatter.Tags,
+		}
+		if date, ok := op.postDate(post); ok {
+			item.PubDate = date.Format(time.RFC1123Z)
+		}
+		feed.Channel.Items = append(feed.Channel.Items, item)
+	}
+
+	return feed
+}
+
+func (op *OutputProcessor) buildAtomFeed(title, subtitle, selfURL string, posts []content.Post) atomFeed {
+	feed := atomFeed{
+		Title:    title,
+		Subtitle: subtitle,
+		ID:       op.siteURL(),
+		Updated:  time.Unix(0, 0).UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: selfURL, Rel: "self", Type: "application/atom+xml"},
+			{Href: op.siteURL(), Rel: "alternate", Type: "text/html"},
+		},
+	}
+
+	if len(posts) > 0 {
+		if date, ok := op.postDate(posts[0]); ok {
+			feed.Updated = date.Format(time.RFC3339)
+		}
+	}
+
+	for _, post := range posts {
+		link := op.postURL(post)
+		entry := atomEntry{
+			Title:   post.FrontMatter.Title,
+			ID:      link,
+			Links:   []atomLink{{Href: link, Rel: "alternate", Type: "text/html"}},
+			Updated: feed.Updated,
+		}
+		if date, ok := op.postDate(post); ok {
+			entry.Published = date.Format(time.RFC3339)
+			entry.Updated = entry.Published
+		}
+		if post.FrontMatter.Author != "" {
+			entry.Author = &atomPerson{Name: post.FrontMatter.Author}
+		}
+		if op.config.FeedContent == config.FeedContentFull {
+			entry.Content = &atomText{Type: "html", Value: op.feedContent(post)}
+		} else {
+			entry.Summary = &atomText{Type: "html", Value: op.feedContent(post)}
+		}
+		for _, tag := range post.FrontMatter.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	return feed
+}
+
+func (op *OutputProcessor) buildJSONFeed(title, description, feedURL string, posts []content.Post) jsonFeed {
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       title,
+		HomePageURL: op.siteURL(),
+		FeedURL:     feedURL,
+		Description: description,
+		Items:       []jsonFeedItem{},
+	}
+
+	for _, post := range posts {
+		link := op.postURL(post)
+		item := jsonFeedItem{
+			ID:          link,
+			URL:         link,
+			Title:       post.FrontMatter.Title,
+			ContentHTML: op.feedContent(post),
+			Summary:     post.Excerpt,
+			Tags:        post.FrontMatter.Tags,
+		}
+		if date, ok := op.postDate(post); ok {
+			item.DatePublished = date.Format(time.RFC3339)
+		}
+		if post.FrontMatter.Author != "" {
+			item.Authors = []jsonFeedAuthor{{Name: post.FrontMatter.Author}}
+		}
+		feed.Items = append(feed.Items, item)
+	}
+
+	return feed
+}
+
+func (op *OutputProcessor) feedContent(post content.Post) string {
+	if op.config.FeedContent == config.FeedContentFull {
+		if post.HTML != "" {
+			return post.HTML
+		}
+		return "<pre>" + html.EscapeString(post.Markdown) + "</pre>"
+	}
+
+	if post.ExcerptHTML != "" {
+		return post.ExcerptHTML
+	}
+	return "<p>" + html.EscapeString(post.Excerpt) + "</p>"
+}
+
+func (op *OutputProcessor) postDate(post content.Post) (time.Time, bool) {
+	date, err := time.Parse(op.config.DateFormat, post.FrontMatter.Date)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+func (op *OutputProcessor) siteURL() string {
+	return strings.TrimRight(op.config.SiteURL, "/")
+}
+
+func (op *OutputProcessor) postURL(post content.Post) string {
+	return op.siteURL() + post.Permalink
+}
+
+func (op *OutputProcessor) apiURL(resource string) string {
+	return op.siteURL() + "/api/" + resource
+}
+
+func (op *OutputProcessor) saveXML(path string, data interface{}) error {
+	xmlData, err := xml.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal XML: %w", err)
+	}
+
+	return op.writeFile(path, append([]byte(xml.Header), xmlData...))
+}
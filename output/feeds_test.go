@@ -0,0 +1,93 @@
+package output
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"io/fs"
+	"log"
+	"path/filepath"
+	"testing"
+
+	"github.com/tech-arch1tect/mantle/cache"
+	"github.com/tech-arch1tect/mantle/config"
+	"github.com/tech-arch1tect/mantle/process"
+)
+
+func newTestOutputProcessor(t *testing.T) (*OutputProcessor, *OutputWriter) {
+	t.Helper()
+	cfg := config.NewConfig()
+	cfg.OutputDir = t.TempDir()
+	cfg.SiteURL = "https://example.com/"
+	buildCache := cache.NewMemoryBuildCache(cfg)
+	writer := NewMemoryOutputWriter(cfg.OutputDir, buildCache)
+	op := NewOutputProcessor(cfg, buildCache, writer)
+	op.SetLogger(log.New(io.Discard, "", 0))
+	return op, writer
+}
+
+func TestTaxonomyFeedLinksAreEscaped(t *testing.T) {
+	op, writer := newTestOutputProcessor(t)
+	if err := op.saveTagFeeds(map[string][]string{"Go Lang": nil, "c#": nil}, nil); err != nil {
+		t.Fatal(err)
+	}
+	categories := map[string]process.CategoryInfo{"tips & tricks/go": {Name: "go", Path: "tips & tricks/go"}}
+	if err := op.saveCategoryFeeds(categories, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"api/tags/Go Lang/feed.xml", "https://example.com/api/tags/Go%20Lang/feed.xml"},
+		{"api/tags/c#/feed.xml", "https://example.com/api/tags/c%23/feed.xml"},
+		{"api/categories/tips & tricks_go/feed.xml", "https://example.com/api/categories/tips%20&%20tricks_go/feed.xml"},
+	}
+	files := writer.FS(filepath.Join(op.config.OutputDir, "public_html"))
+	for _, tt := range tests {
+		data, err := fs.ReadFile(files, tt.file)
+		if err != nil {
+			t.Errorf("%s: %v", tt.file, err)
+			continue
+		}
+		var href bytes.Buffer
+		_ = xml.EscapeText(&href, []byte(tt.want))
+		if !bytes.Contains(data, []byte(`href="`+href.String()+`"`)) {
+			t.Errorf("%s does not link to itself as %s:\n%s", tt.file, tt.want, data)
+		}
+	}
+}
+
+func TestTaxonomyFilesStayInTheirDirectory(t *testing.T) {
+	op, writer := newTestOutputProcessor(t)
+	tags := map[string][]string{"..": nil, "a/b": nil}
+	if err := op.saveTags(tags, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := op.saveTagFeeds(tags, nil); err != nil {
+		t.Fatal(err)
+	}
+	categories := map[string]process.CategoryInfo{"..": {Name: "..", Path: ".."}}
+	if err := op.saveCategoryFeeds(categories, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	files := writer.FS(filepath.Join(op.config.OutputDir, "public_html"))
+	for _, name := range []string{
+		"api/tags/__.json",
+		"api/tags/__/feed.xml",
+		"api/tags/a_b.json",
+		"api/tags/a_b/feed.xml",
+		"api/categories/__/feed.xml",
+	} {
+		if _, err := fs.Stat(files, name); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	for _, name := range []string{"api/feed.xml", "api/tags/a", "feed.xml"} {
+		if _, err := fs.Stat(files, name); err == nil {
+			t.Errorf("%s was written outside the directory of its tag", name)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/tech-arch1tect/mantle/cache"
+	"github.com/tech-arch1tect/mantle/content"
+	"github.com/tech-arch1tect/mantle/internal/imaging"
+)
+
+// saveMedia copies the assets of page bundles to public_html/media/{slug}/,
+// the location their URLs point to, along with the resized variants of images.
+func (op *OutputProcessor) saveMedia(posts []content.Post) error {
+	count := 0
+	for _, post := range posts {
+		for _, asset := range post.Assets {
+			data, err := os.ReadFile(filepath.Join(op.config.ContentDir, filepath.FromSlash(asset.SourcePath)))
+			if err != nil {
+				return fmt.Errorf("failed to read asset %s: %w", asset.SourcePath, err)
+			}
+
+			mediaPath := filepath.Join(op.config.OutputDir, "public_html", "media", post.FrontMatter.Slug,
+				filepath.FromSlash(asset.Name))
+			if err := op.writeFile(mediaPath, data); err != nil {
+				return fmt.Errorf("failed to save asset %s: %w", asset.SourcePath, err)
+			}
+			count++
+
+			if asset.Image != nil && len(asset.Image.Variants) > 0 {
+				if err := op.saveImageVariants(post, asset, data); err != nil {
+					return fmt.Errorf("failed to save variants of %s: %w", asset.SourcePath, err)
+				}
+			}
+		}
+	}
+
+	if count > 0 {
+		op.logger.Printf("Saved %d bundle asset(s), encoding %d image variant(s)", count, op.encodedVariants)
+	}
+	return nil
+}
+
+// saveImageVariants resizes and encodes the variants of an image. Encoding is
+// slow, so variants are kept from the previous build when the image and the
+// variants are unchanged.
+func (op *OutputProcessor) saveImageVariants(post content.Post, asset content.Asset, data []byte) error {
+	paths := make([]string, len(asset.Image.Variants))
+	for i, variant := range asset.Image.Variants {
+		name, err := url.PathUnescape(variant.URL)
+		if err != nil {
+			return fmt.Errorf("invalid variant URL %s: %w", variant.URL, err)
+		}
+		paths[i] = filepath.Join(op.config.OutputDir, "public_html", filepath.FromSlash(name))
+	}
+
+	key := "variants:" + post.FrontMatter.Slug + ":" + asset.SourcePath
+	inputHash := cache.HashJSON([]interface{}{cache.HashBytes(data), asset.Image.Variants, op.config.ImageQuality})
+	if op.cache.LookupAggregate(key, inputHash, nil) && op.keepAll(paths) {
+		return nil
+	}
+
+	img, err := imaging.Decode(data)
+	if err != nil {
+		return err
+	}
+	for i, variant := range asset.Image.Variants {
+		var buf bytes.Buffer
+		if err := imaging.Encode(&buf, imaging.Resize(img, variant.Width), variant.Format, op.config.ImageQuality); err != nil {
+			return fmt.Errorf("failed to encode %d pixel %s variant: %w", variant.Width, variant.Format, err)
+		}
+		op.encodedVariants++
+		if err := op.writeFile(paths[i], buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return op.cache.StoreAggregate(key, inputHash, nil)
+}
+
+func (op *OutputProcessor) keepAll(paths []string) bool {
+	for _, path := range paths {
+		if !op.writer.Keep(path) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package output
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tech-arch1tect/mantle/cache"
+	"github.com/tech-arch1tect/mantle/config"
+	"github.com/tech-arch1tect/mantle/content"
+	"github.com/tech-arch1tect/mantle/process"
+)
+
+// buildMedia loads the posts in cfg.ContentDir and writes their media with the
+// build cache, as a build does, and returns the number of variants encoded.
+func buildMedia(t *testing.T, cfg *config.Config) int {
+	t.Helper()
+	buildCache, err := cache.LoadBuildCache(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publishFilter, err := content.NewPublishFilter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loader := content.NewPostLoader(cfg, publishFilter, buildCache)
+	loader.SetLogger(log.New(io.Discard, "", 0))
+	posts, err := loader.LoadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	processed := process.NewPostProcessor(cfg, buildCache).Process(posts)
+	op := NewOutputProcessor(cfg, buildCache, NewOutputWriter(cfg.OutputDir, buildCache))
+	op.SetLogger(log.New(io.Discard, "", 0))
+	if err := op.saveMedia(processed.Posts); err != nil {
+		t.Fatal(err)
+	}
+	if err := buildCache.Save(); err != nil {
+		t.Fatal(err)
+	}
+	return op.encodedVariants
+}
+
+func TestSharedImageVariantsAreCached(t *testing.T) {
+	contentDir := t.TempDir()
+	var photo bytes.Buffer
+	if err := png.Encode(&photo, image.NewRGBA(image.Rect(0, 0, 8, 8))); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{
+		"first.md":         []byte("---\ntitle: First\nauthor: A\ndate: 2024-01-01\n---\n![Photo](images/photo.png)\n"),
+		"second.md":        []byte("---\ntitle: Second\nauthor: A\ndate: 2024-01-02\n---\n![Photo](images/photo.png)\n"),
+		"images/photo.png": photo.Bytes(),
+	}
+	for name, data := range files {
+		path := filepath.Join(contentDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.NewConfig()
+	cfg.ContentDir = contentDir
+	cfg.OutputDir = t.TempDir()
+	cfg.ProcessImages = true
+	cfg.ImageWidths = "2,4"
+	cfg.ImageFormats = "webp,jpeg"
+
+	if encoded := buildMedia(t, cfg); encoded != 8 {
+		t.Fatalf("first build encoded %d variant(s), want 8", encoded)
+	}
+	if encoded := buildMedia(t, cfg); encoded != 0 {
+		t.Errorf("second build encoded %d variant(s), want all of them kept", encoded)
+	}
+	for _, slug := range []string{"first", "second"} {
+		if _, err := os.Stat(filepath.Join(cfg.OutputDir, "public_html", "media", slug, "photo.png-4w.webp")); err != nil {
+			t.Errorf("variant of %s: %v", slug, err)
+		}
+	}
+}
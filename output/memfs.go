@@ -0,0 +1,98 @@
+package output
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"time"
+
+	"github.com/tech-arch1tect/mantle/cache"
+)
+
+// ContentHash is exposed through fs.FileInfo.Sys by in-memory files so the
+// router can derive entity tags from file content.
+type ContentHash string
+
+// memoryFS is a read-only fs.FS over generated files held in memory.
+type memoryFS struct {
+	files   map[string][]byte
+	dirs    map[string]bool
+	hashes  map[string]ContentHash
+	modTime time.Time
+}
+
+func newMemoryFS(files map[string][]byte, modTime time.Time) *memoryFS {
+	mfs := &memoryFS{
+		files:   files,
+		dirs:    map[string]bool{".": true},
+		hashes:  make(map[string]ContentHash, len(files)),
+		modTime: modTime,
+	}
+	for name, data := range files {
+		mfs.hashes[name] = ContentHash(cache.HashBytes(data)[:32])
+		for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+			mfs.dirs[dir] = true
+		}
+	}
+	return mfs
+}
+
+func (mfs *memoryFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if data, ok := mfs.files[name]; ok {
+		info := memoryFileInfo{name: path.Base(name), size: int64(len(data)), modTime: mfs.modTime, hash: mfs.hashes[name]}
+		return &memoryFile{Reader: bytes.NewReader(data), info: info}, nil
+	}
+	if mfs.dirs[name] {
+		info := memoryFileInfo{name: path.Base(name), modTime: mfs.modTime, dir: true}
+		return &memoryFile{Reader: bytes.NewReader(nil), info: info}, nil
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+type memoryFile struct {
+	*bytes.Reader
+	info memoryFileInfo
+}
+
+func (mf *memoryFile) Stat() (fs.FileInfo, error) {
+	return mf.info, nil
+}
+
+func (mf *memoryFile) Read(p []byte) (int, error) {
+	if mf.info.dir {
+		return 0, &fs.PathError{Op: "read", Path: mf.info.name, Err: fs.ErrInvalid}
+	}
+	return mf.Reader.Read(p)
+}
+
+func (mf *memoryFile) Close() error {
+	return nil
+}
+
+var _ io.ReadSeeker = (*memoryFile)(nil)
+
+type memoryFileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+	hash    ContentHash
+}
+
+func (fi memoryFileInfo) Name() string       { return fi.name }
+func (fi memoryFileInfo) Size() int64        { return fi.size }
+func (fi memoryFileInfo) ModTime() time.Time { return fi.modTime }
+func (fi memoryFileInfo) IsDir() bool        { return fi.dir }
+func (fi memoryFileInfo) Sys() interface{}   { return fi.hash }
+
+func (fi memoryFileInfo) Mode() fs.FileMode {
+	if fi.dir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
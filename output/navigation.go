@@ -0,0 +1,77 @@
+package output
+
+import "github.com/tech-arch1tect/mantle/content"
+
+// attachNavigation links every post in posts, which are sorted newest first,
+// to the posts published just before (previous) and after (next) it. With
+// TAXONOMY_NAVIGATION, posts are also linked within their category and within
+// each of their tags.
+func (op *OutputProcessor) attachNavigation(posts []content.Post) {
+	all := make([]int, len(posts))
+	for i := range posts {
+		all[i] = i
+	}
+	linkNeighbours(posts, all, func(post *content.Post, nav content.PostNav) {
+		post.Previous, post.Next = nav.Previous, nav.Next
+	})
+
+	if !op.config.TaxonomyNavigation {
+		return
+	}
+
+	byCategory := make(map[string][]int)
+	byTag := make(map[string][]int)
+	for i, post := range posts {
+		if category := post.FrontMatter.Category; category != "" {
+			byCategory[category] = append(byCategory[category], i)
+		}
+		for _, tag := range post.FrontMatter.Tags {
+			// A tag listed twice on one post would make the post its own neighbour.
+			if indexes := byTag[tag]; len(indexes) > 0 && indexes[len(indexes)-1] == i {
+				continue
+			}
+			byTag[tag] = append(byTag[tag], i)
+		}
+	}
+
+	// Posts alone in a category or tag have no neighbours there to link to.
+	for _, indexes := range byCategory {
+		if len(indexes) < 2 {
+			continue
+		}
+		linkNeighbours(posts, indexes, func(post *content.Post, nav content.PostNav) {
+			post.CategoryNav = &nav
+		})
+	}
+	for tag, indexes := range byTag {
+		if len(indexes) < 2 {
+			continue
+		}
+		linkNeighbours(posts, indexes, func(post *content.Post, nav content.PostNav) {
+			if post.TagNav == nil {
+				post.TagNav = make(map[string]content.PostNav)
+			}
+			post.TagNav[tag] = nav
+		})
+	}
+}
+
+// linkNeighbours passes assign the older and newer neighbours of each of the
+// posts at indexes, which are in newest-first order.
+func linkNeighbours(posts []content.Post, indexes []int, assign func(post *content.Post, nav content.PostNav)) {
+	summary := func(i int) *content.PostSummary {
+		summary := content.NewPostSummary(posts[i])
+		return &summary
+	}
+
+	for n, i := range indexes {
+		var nav content.PostNav
+		if n < len(indexes)-1 {
+			nav.Previous = summary(indexes[n+1])
+		}
+		if n > 0 {
+			nav.Next = summary(indexes[n-1])
+		}
+		assign(&posts[i], nav)
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package output
 
 import (
 	"encoding/json"
@@ -10,6 +10,11 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/tech-arch1tect/mantle/cache"
+	"github.com/tech-arch1tect/mantle/config"
+	"github.com/tech-arch1tect/mantle/content"
+	"github.com/tech-arch1tect/mantle/process"
 )
 
 var tokenRegex = regexp.MustCompile(`[A-Za-z0-9_]+`)
@@ -71,19 +76,64 @@ type MetadataResponse struct {
 	} `json:"site"`
 }
 
+// @Description Error response format
+type ErrorResponse struct {
+	Error   string `json:"error" example:"Not found"`
+	Message string `json:"message,omitempty" example:"The requested resource was not found"`
+}
+
+// @Description Pagination information for responses
+type PaginationInfo struct {
+	Page        int  `json:"page" example:"0"`
+	TotalPages  int  `json:"totalPages" example:"5"`
+	TotalItems  int  `json:"totalItems" example:"42"`
+	HasNext     bool `json:"hasNext" example:"true"`
+	HasPrevious bool `json:"hasPrevious" example:"false"`
+	NextPage    *int `json:"nextPage,omitempty" example:"1"`
+	PrevPage    *int `json:"prevPage,omitempty" example:"0"`
+}
+
+// @Description Paginated response containing posts and pagination metadata
+type PostsResponse struct {
+	Posts []content.Post `json:"posts"`
+	PaginationInfo
+}
+
+// @Description Paginated response containing post previews and pagination metadata
+type PreviewsResponse struct {
+	Previews []content.PostPreview `json:"previews"`
+	PaginationInfo
+}
+
 type OutputProcessor struct {
-	config *Config
+	config *config.Config
 	logger *log.Logger
+	cache  *cache.BuildCache
+	writer *OutputWriter
+	// dates holds the publication date of every post whose date could be
+	// parsed, by slug. It is filled by sortPostsByDate.
+	dates map[string]time.Time
+	// encodedVariants counts the image variants encoded by this build, as
+	// opposed to kept from the previous one.
+	encodedVariants int
 }
 
-func NewOutputProcessor(config *Config) *OutputProcessor {
+func NewOutputProcessor(cfg *config.Config, cache *cache.BuildCache, writer *OutputWriter) *OutputProcessor {
 	return &OutputProcessor{
-		config: config,
+		config: cfg,
 		logger: log.New(os.Stdout, "[OutputProcessor] ", log.LstdFlags),
+		cache:  cache,
+		writer: writer,
+		dates:  make(map[string]time.Time),
 	}
 }
 
-func (op *OutputProcessor) Process(processedPosts ProcessedPosts) error {
+// SetLogger replaces the logger that progress and warnings are logged to.
+func (op *OutputProcessor) SetLogger(logger *log.Logger) {
+	op.logger = logger
+}
+
+func (op *OutputProcessor) Process(processedPosts process.ProcessedPosts) error {
 	op.logger.Println("Processing output...")
 
 	if err := op.createDirectories(); err != nil {
@@ -95,23 +145,31 @@ func (op *OutputProcessor) Process(processedPosts ProcessedPosts) error {
 		return fmt.Errorf("failed to sort posts: %w", err)
 	}
 
-	if err := op.savePosts(sortedPosts); err != nil {
+	op.attachNavigation(sortedPosts)
+
+	formattedPosts := op.applyContentFormat(sortedPosts)
+
+	if err := op.savePosts(formattedPosts); err != nil {
 		return fmt.Errorf("failed to save posts: %w", err)
 	}
 
-	if err := op.savePostPreviews(sortedPosts); err != nil {
+	if err := op.saveMedia(sortedPosts); err != nil {
+		return fmt.Errorf("failed to save media: %w", err)
+	}
+
+	if err := op.savePostPreviews(formattedPosts); err != nil {
 		return fmt.Errorf("failed to save post previews: %w", err)
 	}
 
-	if err := op.saveTags(processedPosts.Tags, sortedPosts); err != nil {
+	if err := op.saveTags(processedPosts.Tags, formattedPosts); err != nil {
 		return fmt.Errorf("failed to save tags: %w", err)
 	}
 
-	if err := op.saveCategories(processedPosts.Categories, sortedPosts); err != nil {
+	if err := op.saveCategories(processedPosts.Categories, formattedPosts); err != nil {
 		return fmt.Errorf("failed to save categories: %w", err)
 	}
 
-	if err := op.savePaginatedPosts(sortedPosts); err != nil {
+	if err := op.savePaginatedPosts(formattedPosts); err != nil {
 		return fmt.Errorf("failed to save paginated posts: %w", err)
 	}
 
@@ -119,19 +177,48 @@ func (op *OutputProcessor) Process(processedPosts ProcessedPosts) error {
 		return fmt.Errorf("failed to save related posts: %w", err)
 	}
 
+	if err := op.saveBacklinks(processedPosts.Backlinks); err != nil {
+		return fmt.Errorf("failed to save backlinks: %w", err)
+	}
+
+	if err := op.saveArchives(formattedPosts); err != nil {
+		return fmt.Errorf("failed to save archives: %w", err)
+	}
+
+	if err := op.saveSeries(processedPosts.Series, formattedPosts); err != nil {
+		return fmt.Errorf("failed to save series: %w", err)
+	}
+
 	if err := op.saveSearchIndex(sortedPosts); err != nil {
 		return fmt.Errorf("failed to save search index: %w", err)
 	}
 
+	if err := op.saveFeeds(sortedPosts, processedPosts); err != nil {
+		return fmt.Errorf("failed to save feeds: %w", err)
+	}
+
+	if err := op.saveSitemap(sortedPosts); err != nil {
+		return fmt.Errorf("failed to save sitemap: %w", err)
+	}
+
+	if err := op.saveRobotsTxt(); err != nil {
+		return fmt.Errorf("failed to save robots.txt: %w", err)
+	}
+
 	if err := op.saveUnifiedMetadata(sortedPosts, processedPosts); err != nil {
 		return fmt.Errorf("failed to save unified metadata: %w", err)
 	}
 
-	op.logger.Println("Output processed successfully")
+	if err := op.saveHighlightStylesheet(); err != nil {
+		return fmt.Errorf("failed to save highlight stylesheet: %w", err)
+	}
+
+	written, unchanged := op.writer.Stats()
+	op.logger.Printf("Output processed successfully (%d file(s) written, %d unchanged)", written, unchanged)
 	return nil
 }
 
-func (op *OutputProcessor) saveUnifiedMetadata(sortedPosts []Post, processedPosts ProcessedPosts) error {
+func (op *OutputProcessor) saveUnifiedMetadata(sortedPosts []content.Post, processedPosts process.ProcessedPosts) error {
 	totalPosts := len(sortedPosts)
 	postsPerPage := op.config.PostsPerPage
 	previewsPerPage := op.config.PreviewsPerPage
@@ -212,7 +299,7 @@ func (op *OutputProcessor) saveUnifiedMetadata(sortedPosts []Post, processedPost
 	return nil
 }
 
-func (op *OutputProcessor) saveRelatedPosts(relatedPosts map[string][]RelatedPost) error {
+func (op *OutputProcessor) saveRelatedPosts(relatedPosts map[string][]process.RelatedPost) error {
 	allRelatedPath := filepath.Join(op.config.OutputDir, "public_html", "api", "related", "all.json")
 	if err := op.saveJSON(allRelatedPath, relatedPosts); err != nil {
 		return fmt.Errorf("failed to save all related posts: %w", err)
@@ -230,12 +317,96 @@ func (op *OutputProcessor) saveRelatedPosts(relatedPosts map[string][]RelatedPos
 	return nil
 }
 
-func (op *OutputProcessor) sortPostsByDate(posts []Post) ([]Post, error) {
-	sorted := make([]Post, len(posts))
+func (op *OutputProcessor) saveBacklinks(backlinks map[string][]process.Backlink) error {
+	allBacklinksPath := filepath.Join(op.config.OutputDir, "public_html", "api", "backlinks", "all.json")
+	if err := op.saveJSON(allBacklinksPath, backlinks); err != nil {
+		return fmt.Errorf("failed to save all backlinks: %w", err)
+	}
+
+	for postSlug, links := range backlinks {
+		backlinksPath := filepath.Join(op.config.OutputDir, "public_html", "api", "backlinks",
+			fmt.Sprintf("%s.json", postSlug))
+		if err := op.saveJSON(backlinksPath, links); err != nil {
+			return fmt.Errorf("failed to save backlinks for post %s: %w", postSlug, err)
+		}
+	}
+
+	op.logger.Printf("Saved backlinks for %d posts", len(backlinks))
+	return nil
+}
+
+func (op *OutputProcessor) saveSeries(series map[string]process.SeriesInfo, allPosts []content.Post) error {
+	allSeriesPath := filepath.Join(op.config.OutputDir, "public_html", "api", "series", "all.json")
+	if err := op.saveJSON(allSeriesPath, series); err != nil {
+		return fmt.Errorf("failed to save all series: %w", err)
+	}
+
+	postsBySlug := make(map[string]content.Post, len(allPosts))
+	for _, post := range allPosts {
+		postsBySlug[post.FrontMatter.Slug] = post
+	}
+
+	for slug, info := range series {
+		previews := make([]content.PostPreview, 0, len(info.PostSlugs))
+		for _, postSlug := range info.PostSlugs {
+			if post, ok := postsBySlug[postSlug]; ok {
+				previews = append(previews, content.NewPostPreview(post))
+			}
+		}
+		seriesPath := filepath.Join(op.config.OutputDir, "public_html", "api", "series", fmt.Sprintf("%s.json", slug))
+		if err := op.saveJSON(seriesPath, previews); err != nil {
+			return fmt.Errorf("failed to save series %s: %w", slug, err)
+		}
+	}
+
+	if len(series) > 0 {
+		op.logger.Printf("Saved %d series", len(series))
+	}
+	return nil
+}
+
+func (op *OutputProcessor) saveHighlightStylesheet() error {
+	if op.config.ContentFormat == config.ContentFormatMarkdown || !op.config.HighlightCode {
+		return nil
+	}
+
+	stylesheet, err := content.HighlightStylesheet(op.config.HighlightStyle)
+	if err != nil {
+		return fmt.Errorf("failed to generate stylesheet: %w", err)
+	}
+
+	stylesheetPath := filepath.Join(op.config.OutputDir, "public_html", "api", "assets", "highlight.css")
+	if err := op.writeFile(stylesheetPath, stylesheet); err != nil {
+		return err
+	}
+
+	op.logger.Printf("Saved highlight stylesheet using style %q", op.config.HighlightStyle)
+	return nil
+}
+
+func (op *OutputProcessor) applyContentFormat(posts []content.Post) []content.Post {
+	formatted := make([]content.Post, len(posts))
+	for i, post := range posts {
+		switch op.config.ContentFormat {
+		case config.ContentFormatMarkdown:
+			post.HTML = ""
+			post.ExcerptHTML = ""
+		case config.ContentFormatHTML:
+			post.Markdown = ""
+			post.Excerpt = ""
+			post.HTMLOnly = true
+		}
+		formatted[i] = post
+	}
+	return formatted
+}
+
+func (op *OutputProcessor) sortPostsByDate(posts []content.Post) ([]content.Post, error) {
+	sorted := make([]content.Post, len(posts))
 	copy(sorted, posts)
 
 	type postWithDate struct {
-		post Post
+		post content.Post
 		date time.Time
 	}
 
@@ -247,6 +418,8 @@ func (op *OutputProcessor) sortPostsByDate(posts []Post) ([]Post, error) {
 			op.logger.Printf("Warning: Failed to parse date '%s' for post %s using format '%s': %v",
 				post.FrontMatter.Date, post.FrontMatter.Slug, op.config.DateFormat, err)
 			parsedDate = time.Unix(0, 0)
+		} else {
+			op.dates[post.FrontMatter.Slug] = parsedDate
 		}
 		postsWithDates = append(postsWithDates, postWithDate{
 			post: post,
@@ -254,7 +427,7 @@ func (op *OutputProcessor) sortPostsByDate(posts []Post) ([]Post, error) {
 		})
 	}
 
-	sort.Slice(postsWithDates, func(i, j int) bool {
+	sort.SliceStable(postsWithDates, func(i, j int) bool {
 		return postsWithDates[i].date.After(postsWithDates[j].date)
 	})
 
@@ -265,23 +438,30 @@ func (op *OutputProcessor) sortPostsByDate(posts []Post) ([]Post, error) {
 	return sorted, nil
 }
 
-func (op *OutputProcessor) saveCategories(categories map[string]CategoryInfo, allPosts []Post) error {
+// taxonomyFileName returns the single path segment a tag or category is
+// published under. Slashes become underscores, and so do the dots of a name
+// made only of dots, which would otherwise refer to a parent directory.
+func taxonomyFileName(name string) string {
+	name = strings.ReplaceAll(name, "/", "_")
+	if strings.Trim(name, ".") == "" {
+		name = strings.Repeat("_", len(name))
+	}
+	return name
+}
+
+func (op *OutputProcessor) saveCategories(categories map[string]process.CategoryInfo, allPosts []content.Post) error {
 	allCategoriesPath := filepath.Join(op.config.OutputDir, "public_html", "api", "categories", "all.json")
 	if err := op.saveJSON(allCategoriesPath, categories); err != nil {
 		return fmt.Errorf("failed to save all categories: %w", err)
 	}
 
 	for categoryPath, info := range categories {
-		safeFilename := strings.ReplaceAll(categoryPath, "/", "_")
-		var previews []PostPreview
+		safeFilename := taxonomyFileName(categoryPath)
+		var previews []content.PostPreview
 		for _, slug := range info.PostSlugs {
 			for _, post := range allPosts {
 				if post.FrontMatter.Slug == slug {
-					previews = append(previews, PostPreview{
-						FrontMatter: post.FrontMatter,
-						Excerpt:     post.Excerpt,
-						ReadingTime: post.ReadingTime,
-					})
+					previews = append(previews, content.NewPostPreview(post))
 					break
 				}
 			}
@@ -306,7 +486,7 @@ func (op *OutputProcessor) saveCategories(categories map[string]CategoryInfo, al
 	return nil
 }
 
-func (op *OutputProcessor) buildCategoryTree(categories map[string]CategoryInfo) CategoryTree {
+func (op *OutputProcessor) buildCategoryTree(categories map[string]process.CategoryInfo) CategoryTree {
 	var roots CategoryTree
 
 	for path, info := range categories {
@@ -322,7 +502,7 @@ func (op *OutputProcessor) buildCategoryTree(categories map[string]CategoryInfo)
 	return roots
 }
 
-func (op *OutputProcessor) buildTreeNode(path string, categories map[string]CategoryInfo) CategoryTreeNode {
+func (op *OutputProcessor) buildTreeNode(path string, categories map[string]process.CategoryInfo) CategoryTreeNode {
 	info := categories[path]
 	node := CategoryTreeNode{
 		Name:      info.Name,
@@ -341,7 +521,7 @@ func (op *OutputProcessor) buildTreeNode(path string, categories map[string]Cate
 	return node
 }
 
-func (op *OutputProcessor) savePaginatedPosts(posts []Post) error {
+func (op *OutputProcessor) savePaginatedPosts(posts []content.Post) error {
 	postsPerPage := op.config.PostsPerPage
 
 	totalPages := (len(posts) + postsPerPage - 1) / postsPerPage
@@ -401,13 +581,23 @@ func (op *OutputProcessor) savePaginatedPosts(posts []Post) error {
 	return nil
 }
 
-func (op *OutputProcessor) savePosts(posts []Post) error {
+func (op *OutputProcessor) savePosts(posts []content.Post) error {
 	for _, post := range posts {
 		postPath := filepath.Join(op.config.OutputDir, "public_html", "api", "posts", "by-slug",
 			fmt.Sprintf("%s.json", post.FrontMatter.Slug))
 		if err := op.saveJSON(postPath, post); err != nil {
 			return fmt.Errorf("failed to save post %s: %w", post.FrontMatter.Slug, err)
 		}
+
+		tocPath := filepath.Join(op.config.OutputDir, "public_html", "api", "posts", "toc",
+			fmt.Sprintf("%s.json", post.FrontMatter.Slug))
+		toc := post.TOC
+		if toc == nil {
+			toc = []content.TOCEntry{}
+		}
+		if err := op.saveJSON(tocPath, toc); err != nil {
+			return fmt.Errorf("failed to save table of contents for post %s: %w", post.FrontMatter.Slug, err)
+		}
 	}
 
 	allPostsPath := filepath.Join(op.config.OutputDir, "public_html", "api", "posts", "all.json")
@@ -418,15 +608,10 @@ func (op *OutputProcessor) savePosts(posts []Post) error {
 	return nil
 }
 
-func (op *OutputProcessor) savePostPreviews(posts []Post) error {
-	previews := make([]PostPreview, 0, len(posts))
+func (op *OutputProcessor) savePostPreviews(posts []content.Post) error {
+	previews := make([]content.PostPreview, 0, len(posts))
 	for _, post := range posts {
-		preview := PostPreview{
-			FrontMatter: post.FrontMatter,
-			Excerpt:     post.Excerpt,
-			ReadingTime: post.ReadingTime,
-		}
-		previews = append(previews, preview)
+		previews = append(previews, content.NewPostPreview(post))
 	}
 
 	for _, preview := range previews {
@@ -437,12 +622,47 @@ func (op *OutputProcessor) savePostPreviews(posts []Post) error {
 		}
 	}
 
+	previewsPerPage := op.config.PreviewsPerPage
+	pages := op.paginatePreviews(previews)
+	totalPages := len(pages)
+
+	for page, paginated := range pages {
+		pagePath := filepath.Join(op.config.OutputDir, "public_html", "api", "previews", "by-page",
+			fmt.Sprintf("%d.json", page))
+		if err := op.saveJSON(pagePath, paginated); err != nil {
+			return fmt.Errorf("failed to save preview page %d: %w", page, err)
+		}
+	}
+
+	allPreviewsPath := filepath.Join(op.config.OutputDir, "public_html", "api", "previews", "all.json")
+	if err := op.saveJSON(allPreviewsPath, previews); err != nil {
+		return fmt.Errorf("failed to save all previews: %w", err)
+	}
+
+	previewMeta := map[string]interface{}{
+		"totalPages":      totalPages,
+		"totalPreviews":   len(previews),
+		"previewsPerPage": previewsPerPage,
+	}
+
+	previewMetaPath := filepath.Join(op.config.OutputDir, "public_html", "api", "previews", "meta.json")
+	if err := op.saveJSON(previewMetaPath, previewMeta); err != nil {
+		return fmt.Errorf("failed to save preview metadata: %w", err)
+	}
+
+	return nil
+}
+
+// paginatePreviews splits previews into pages of PREVIEWS_PER_PAGE. There is
+// always at least one page, even when previews is empty.
+func (op *OutputProcessor) paginatePreviews(previews []content.PostPreview) []PreviewsResponse {
 	previewsPerPage := op.config.PreviewsPerPage
 	totalPages := (len(previews) + previewsPerPage - 1) / previewsPerPage
 	if totalPages == 0 {
 		totalPages = 1
 	}
 
+	pages := make([]PreviewsResponse, 0, totalPages)
 	for page := 0; page < totalPages; page++ {
 		start := page * previewsPerPage
 		end := start + previewsPerPage
@@ -469,54 +689,28 @@ func (op *OutputProcessor) savePostPreviews(posts []Post) error {
 			prevPage := page - 1
 			paginated.PrevPage = &prevPage
 		}
-
-		pagePath := filepath.Join(op.config.OutputDir, "public_html", "api", "previews", "by-page",
-			fmt.Sprintf("%d.json", page))
-		if err := op.saveJSON(pagePath, paginated); err != nil {
-			return fmt.Errorf("failed to save preview page %d: %w", page, err)
-		}
-	}
-
-	allPreviewsPath := filepath.Join(op.config.OutputDir, "public_html", "api", "previews", "all.json")
-	if err := op.saveJSON(allPreviewsPath, previews); err != nil {
-		return fmt.Errorf("failed to save all previews: %w", err)
-	}
-
-	previewMeta := map[string]interface{}{
-		"totalPages":      totalPages,
-		"totalPreviews":   len(previews),
-		"previewsPerPage": previewsPerPage,
+		pages = append(pages, paginated)
 	}
-
-	previewMetaPath := filepath.Join(op.config.OutputDir, "public_html", "api", "previews", "meta.json")
-	if err := op.saveJSON(previewMetaPath, previewMeta); err != nil {
-		return fmt.Errorf("failed to save preview metadata: %w", err)
-	}
-
-	return nil
+	return pages
 }
 
-func (op *OutputProcessor) saveTags(tags map[string][]string, allPosts []Post) error {
+func (op *OutputProcessor) saveTags(tags map[string][]string, allPosts []content.Post) error {
 	allTagsPath := filepath.Join(op.config.OutputDir, "public_html", "api", "tags", "all.json")
 	if err := op.saveJSON(allTagsPath, tags); err != nil {
 		return fmt.Errorf("failed to save all tags: %w", err)
 	}
 
 	for tag, postSlugs := range tags {
-		var previews []PostPreview
+		var previews []content.PostPreview
 		for _, slug := range postSlugs {
 			for _, post := range allPosts {
 				if post.FrontMatter.Slug == slug {
-					previews = append(previews, PostPreview{
-						FrontMatter: post.FrontMatter,
-						Excerpt:     post.Excerpt,
-						ReadingTime: post.ReadingTime,
-					})
+					previews = append(previews, content.NewPostPreview(post))
 					break
 				}
 			}
 		}
-		tagPath := filepath.Join(op.config.OutputDir, "public_html", "api", "tags", fmt.Sprintf("%s.json", tag))
+		tagPath := filepath.Join(op.config.OutputDir, "public_html", "api", "tags", fmt.Sprintf("%s.json", taxonomyFileName(tag)))
 		if err := op.saveJSON(tagPath, previews); err != nil {
 			return fmt.Errorf("failed to save tag %s: %w", tag, err)
 		}
@@ -568,20 +762,16 @@ func (op *OutputProcessor) convertDateFormatToReadable(goFormat string) string {
 }
 
 func (op *OutputProcessor) saveJSON(path string, data interface{}) error {
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return fmt.Errorf("failed to create directory for %s: %w", path, err)
-	}
-
 	jsonData, err := json.MarshalIndent(data, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal JSON: %w", err)
 	}
 
-	if err := os.WriteFile(path, jsonData, 0644); err != nil {
-		return fmt.Errorf("failed to write file %s: %w", path, err)
-	}
+	return op.writeFile(path, jsonData)
+}
 
-	return nil
+func (op *OutputProcessor) writeFile(path string, data []byte) error {
+	return op.writer.Write(path, data)
 }
 
 func (op *OutputProcessor) createDirectories() error {
@@ -589,21 +779,43 @@ func (op *OutputProcessor) createDirectories() error {
 		filepath.Join(op.config.OutputDir, "public_html", "api", "tags"),
 		filepath.Join(op.config.OutputDir, "public_html", "api", "posts", "by-slug"),
 		filepath.Join(op.config.OutputDir, "public_html", "api", "posts", "by-page"),
+		filepath.Join(op.config.OutputDir, "public_html", "api", "posts", "toc"),
 		filepath.Join(op.config.OutputDir, "public_html", "api", "previews", "by-slug"),
 		filepath.Join(op.config.OutputDir, "public_html", "api", "previews", "by-page"),
 		filepath.Join(op.config.OutputDir, "public_html", "api", "categories"),
 		filepath.Join(op.config.OutputDir, "public_html", "api", "related"),
+		filepath.Join(op.config.OutputDir, "public_html", "api", "backlinks"),
+		filepath.Join(op.config.OutputDir, "public_html", "api", "series"),
+		filepath.Join(op.config.OutputDir, "public_html", "api", "archives"),
 		filepath.Join(op.config.OutputDir, "public_html", "api", "search"),
 	}
 	for _, dir := range directories {
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("failed to create directory %s: %w", dir, err)
+		if err := op.writer.MkdirAll(dir); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
-func (op *OutputProcessor) saveSearchIndex(posts []Post) error {
+func (op *OutputProcessor) saveSearchIndex(posts []content.Post) error {
+	path := filepath.Join(op.config.OutputDir, "public_html", "api", "search", "inverted.json")
+
+	type searchInput struct {
+		Slug    string
+		Title   string
+		Tags    []string
+		Excerpt string
+	}
+	inputs := make([]searchInput, 0, len(posts))
+	for _, p := range posts {
+		inputs = append(inputs, searchInput{p.FrontMatter.Slug, p.FrontMatter.Title, p.FrontMatter.Tags, p.Excerpt})
+	}
+
+	inputHash := cache.HashJSON(inputs)
+	if op.cache.LookupAggregate("search", inputHash, nil) && op.writer.Keep(path) {
+		return nil
+	}
+
 	inverted := make(map[string][]string)
 	for _, p := range posts {
 		text := p.FrontMatter.Title + " " + strings.Join(p.FrontMatter.Tags, " ") + " " + p.Excerpt
@@ -624,6 +836,9 @@ func (op *OutputProcessor) saveSearchIndex(posts []Post) error {
 		sort.Strings(unique)
 		inverted[term] = unique
 	}
-	path := filepath.Join(op.config.OutputDir, "public_html", "api", "search", "inverted.json")
-	return op.saveJSON(path, inverted)
+
+	if err := op.saveJSON(path, inverted); err != nil {
+		return err
+	}
+	return op.cache.StoreAggregate("search", inputHash, nil)
 }
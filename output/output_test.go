@@ -0,0 +1,61 @@
+package output
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/tech-arch1tect/mantle/config"
+	"github.com/tech-arch1tect/mantle/content"
+)
+
+func TestContentFormatFields(t *testing.T) {
+	post := content.Post{
+		FrontMatter: content.FrontMatter{Title: "Empty", Slug: "empty"},
+		HTML:        "<p>Body</p>",
+		ExcerptHTML: "<p>Body</p>",
+	}
+
+	tests := []struct {
+		format  string
+		present []string
+		absent  []string
+	}{
+		{config.ContentFormatMarkdown, []string{"markdown", "excerpt"}, []string{"html", "excerptHtml"}},
+		{config.ContentFormatBoth, []string{"markdown", "excerpt", "html", "excerptHtml"}, nil},
+		{config.ContentFormatHTML, []string{"html", "excerptHtml"}, []string{"markdown", "excerpt"}},
+	}
+	previewFields := map[string]bool{"excerpt": true, "excerptHtml": true}
+	for _, tt := range tests {
+		op, _ := newTestOutputProcessor(t)
+		op.config.ContentFormat = tt.format
+		formatted := op.applyContentFormat([]content.Post{post})[0]
+
+		for name, value := range map[string]interface{}{
+			"post":    formatted,
+			"preview": content.NewPostPreview(formatted),
+		} {
+			data, err := json.Marshal(value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fields map[string]json.RawMessage
+			if err := json.Unmarshal(data, &fields); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, key := range tt.present {
+				if name == "preview" && !previewFields[key] {
+					continue
+				}
+				if _, ok := fields[key]; !ok {
+					t.Errorf("CONTENT_FORMAT=%s: %s JSON has no %q: %s", tt.format, name, key, data)
+				}
+			}
+			for _, key := range tt.absent {
+				if _, ok := fields[key]; ok {
+					t.Errorf("CONTENT_FORMAT=%s: %s JSON has %q: %s", tt.format, name, key, data)
+				}
+			}
+		}
+	}
+}
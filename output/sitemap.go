@@ -0,0 +1,90 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+
+	"github.com/tech-arch1tect/mantle/content"
+)
+
+const maxSitemapURLs = 50000
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name         `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 sitemapindex"`
+	Sitemaps []sitemapPointer `xml:"sitemap"`
+}
+
+type sitemapPointer struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+func (op *OutputProcessor) saveSitemap(sortedPosts []content.Post) error {
+	publicDir := filepath.Join(op.config.OutputDir, "public_html")
+
+	urls := []sitemapURL{{Loc: op.siteURL() + "/"}}
+	for _, post := range sortedPosts {
+		entry := sitemapURL{Loc: op.postURL(post)}
+		if date, ok := op.postDate(post); ok {
+			entry.LastMod = date.Format("2006-01-02")
+		}
+		urls = append(urls, entry)
+	}
+	if len(urls) > 1 {
+		urls[0].LastMod = urls[1].LastMod
+	}
+
+	if len(urls) <= maxSitemapURLs {
+		if err := op.saveXML(filepath.Join(publicDir, "sitemap.xml"), sitemapURLSet{URLs: urls}); err != nil {
+			return fmt.Errorf("failed to save sitemap: %w", err)
+		}
+		op.logger.Printf("Saved sitemap with %d URLs", len(urls))
+		return nil
+	}
+
+	var index sitemapIndex
+	for part := 0; part*maxSitemapURLs < len(urls); part++ {
+		start := part * maxSitemapURLs
+		end := start + maxSitemapURLs
+		if end > len(urls) {
+			end = len(urls)
+		}
+
+		filename := fmt.Sprintf("sitemap-%d.xml", part+1)
+		if err := op.saveXML(filepath.Join(publicDir, filename), sitemapURLSet{URLs: urls[start:end]}); err != nil {
+			return fmt.Errorf("failed to save sitemap %s: %w", filename, err)
+		}
+		index.Sitemaps = append(index.Sitemaps, sitemapPointer{
+			Loc:     fmt.Sprintf("%s/%s", op.siteURL(), filename),
+			LastMod: urls[start].LastMod,
+		})
+	}
+
+	if err := op.saveXML(filepath.Join(publicDir, "sitemap.xml"), index); err != nil {
+		return fmt.Errorf("failed to save sitemap index: %w", err)
+	}
+
+	op.logger.Printf("Saved sitemap index with %d sitemaps covering %d URLs", len(index.Sitemaps), len(urls))
+	return nil
+}
+
+func (op *OutputProcessor) saveRobotsTxt() error {
+	robots := fmt.Sprintf("User-agent: *\nAllow: /\n\nSitemap: %s/sitemap.xml\n", op.siteURL())
+
+	robotsPath := filepath.Join(op.config.OutputDir, "public_html", "robots.txt")
+	if err := op.writeFile(robotsPath, []byte(robots)); err != nil {
+		return fmt.Errorf("failed to save robots.txt: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tech-arch1tect/mantle/cache"
+	"github.com/tech-arch1tect/mantle/internal/fsutil"
+)
+
+type OutputWriter struct {
+	root      string
+	cache     *cache.BuildCache
+	files     map[string][]byte
+	written   int
+	unchanged int
+}
+
+func NewOutputWriter(root string, cache *cache.BuildCache) *OutputWriter {
+	return &OutputWriter{
+		root:  root,
+		cache: cache,
+	}
+}
+
+// NewMemoryOutputWriter creates a writer that keeps everything it is given in
+// memory instead of writing below root. The result is available through FS.
+func NewMemoryOutputWriter(root string, cache *cache.BuildCache) *OutputWriter {
+	return &OutputWriter{
+		root:  root,
+		cache: cache,
+		files: make(map[string][]byte),
+	}
+}
+
+// Write stores data at path unless the previous build produced identical
+// content there, in which case the existing file is left untouched.
+func (ow *OutputWriter) Write(path string, data []byte) error {
+	rel, err := ow.relative(path)
+	if err != nil {
+		return err
+	}
+
+	hash := cache.HashBytes(data)
+	if ow.files != nil {
+		ow.files[rel] = data
+		ow.cache.RecordOutput(rel, hash)
+		ow.written++
+		return nil
+	}
+
+	if previous, ok := ow.cache.PreviousOutput(rel); ok && previous == hash && fileExists(path) {
+		ow.cache.RecordOutput(rel, hash)
+		ow.unchanged++
+		return nil
+	}
+
+	if err := fsutil.WriteFileAtomic(path, data); err != nil {
+		return err
+	}
+
+	ow.cache.RecordOutput(rel, hash)
+	ow.written++
+	return nil
+}
+
+// Keep carries a file produced by the previous build over to this build
+// without regenerating it. It reports false when there is nothing to keep.
+func (ow *OutputWriter) Keep(path string) bool {
+	rel, err := ow.relative(path)
+	if err != nil {
+		return false
+	}
+
+	previous, ok := ow.cache.PreviousOutput(rel)
+	if !ok || ow.files != nil || !fileExists(path) {
+		return false
+	}
+
+	ow.cache.RecordOutput(rel, previous)
+	ow.unchanged++
+	return true
+}
+
+// MkdirAll creates dir on disk. It does nothing for in-memory output, where
+// directories exist implicitly.
+func (ow *OutputWriter) MkdirAll(dir string) error {
+	if ow.files != nil {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+	return nil
+}
+
+// FS returns the files written below dir as a read-only filesystem. It is only
+// available for in-memory output.
+func (ow *OutputWriter) FS(dir string) fs.FS {
+	rel, err := ow.relative(dir)
+	if err != nil {
+		return newMemoryFS(nil, time.Now())
+	}
+	prefix := rel + "/"
+	files := make(map[string][]byte)
+	for rel, data := range ow.files {
+		if strings.HasPrefix(rel, prefix) {
+			files[strings.TrimPrefix(rel, prefix)] = data
+		}
+	}
+	return newMemoryFS(files, time.Now())
+}
+
+// PruneStale removes files that the previous build generated but this build
+// did not. Files Mantle never recorded in its manifest are left alone.
+func (ow *OutputWriter) PruneStale() (int, error) {
+	if ow.files != nil {
+		return 0, nil
+	}
+
+	removed := 0
+	for _, rel := range ow.cache.StaleOutputs() {
+		if !filepath.IsLocal(filepath.FromSlash(rel)) {
+			continue
+		}
+
+		path := filepath.Join(ow.root, filepath.FromSlash(rel))
+		if err := os.Remove(path); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return removed, fmt.Errorf("failed to remove stale file %s: %w", path, err)
+		}
+		removed++
+
+		ow.removeEmptyDirs(filepath.Dir(path))
+	}
+	return removed, nil
+}
+
+func (ow *OutputWriter) removeEmptyDirs(dir string) {
+	root := filepath.Clean(ow.root)
+	for dir != root && strings.HasPrefix(dir, root+string(filepath.Separator)) {
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+func (ow *OutputWriter) Stats() (written, unchanged int) {
+	return ow.written, ow.unchanged
+}
+
+func (ow *OutputWriter) relative(path string) (string, error) {
+	rel, err := filepath.Rel(ow.root, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s relative to %s: %w", path, ow.root, err)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,82 @@
+package output
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tech-arch1tect/mantle/cache"
+	"github.com/tech-arch1tect/mantle/config"
+)
+
+func TestPruneStaleStaysInsideOutputDir(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.NewConfig()
+	cfg.OutputDir = filepath.Join(dir, "output")
+
+	tests := []struct {
+		rel     string
+		removed bool
+	}{
+		{"public_html/api/kept.json", false},
+		{"public_html/api/old/stale.json", true},
+		{"../outside.txt", false},
+		{"public_html/../../escaped.txt", false},
+		{filepath.ToSlash(filepath.Join(dir, "absolute.txt")), false},
+	}
+
+	previous, err := cache.LoadBuildCache(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		path := filepath.FromSlash(tt.rel)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(cfg.OutputDir, path)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(tt.rel), 0644); err != nil {
+			t.Fatal(err)
+		}
+		// A manifest edited by hand, or written by a buggy build, may
+		// record paths outside OUTPUT_DIR.
+		previous.RecordOutput(tt.rel, cache.HashBytes([]byte(tt.rel)))
+	}
+	if err := previous.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	bc, err := cache.LoadBuildCache(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer := NewOutputWriter(cfg.OutputDir, bc)
+	kept := filepath.Join(cfg.OutputDir, "public_html", "api", "kept.json")
+	if err := writer.Write(kept, []byte("public_html/api/kept.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := writer.PruneStale()
+	if err != nil {
+		t.Fatalf("PruneStale: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("removed %d file(s), want 1", removed)
+	}
+	for _, tt := range tests {
+		path := filepath.FromSlash(tt.rel)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(cfg.OutputDir, path)
+		}
+		_, err := os.Stat(path)
+		if gone := errors.Is(err, os.ErrNotExist); gone != tt.removed {
+			t.Errorf("%s removed = %t, want %t", tt.rel, gone, tt.removed)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(cfg.OutputDir, "public_html", "api", "old")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("empty directory left behind by the stale file: %v", err)
+	}
+}
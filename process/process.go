@@ -0,0 +1,434 @@
+package process
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tech-arch1tect/mantle/cache"
+	"github.com/tech-arch1tect/mantle/config"
+	"github.com/tech-arch1tect/mantle/content"
+)
+
+type PostProcessor interface {
+	Process(posts []content.Post) ProcessedPosts
+}
+
+// @Description Category information including hierarchy and post associations
+type CategoryInfo struct {
+	Name      string   `json:"name" example:"Tutorials"`
+	Path      string   `json:"path" example:"tech/tutorials"`
+	PostSlugs []string `json:"postSlugs" example:"getting-started-with-go,advanced-go-patterns"`
+	PostCount int      `json:"postCount" example:"2"`
+	Parent    string   `json:"parent,omitempty" example:"tech"`
+	Children  []string `json:"children,omitempty" example:"golang,javascript"`
+}
+
+// @Description Related post information with similarity metrics
+type RelatedPost struct {
+	Slug        string `json:"slug" example:"advanced-go-patterns"`
+	Title       string `json:"title" example:"Advanced Go Patterns"`
+	Date        string `json:"date" example:"2024-01-20"`
+	CommonTags  int    `json:"commonTags" example:"3"`
+	ReadingTime int    `json:"readingTime" example:"8"`
+}
+
+// @Description Post that links to another post
+type Backlink struct {
+	Slug      string `json:"slug" example:"advanced-go-patterns"`
+	Title     string `json:"title" example:"Advanced Go Patterns"`
+	Date      string `json:"date" example:"2024-01-20"`
+	Permalink string `json:"permalink" example:"/advanced-go-patterns"`
+}
+
+// @Description Series of posts meant to be read in order
+type SeriesInfo struct {
+	Name      string   `json:"name" example:"Learning Go"`
+	Slug      string   `json:"slug" example:"learning-go"`
+	PostSlugs []string `json:"postSlugs" example:"go-basics,go-interfaces"`
+	PostCount int      `json:"postCount" example:"2"`
+}
+
+// @Description Complete processed blog data including posts, tags, categories, and relationships
+type ProcessedPosts struct {
+	Posts        []content.Post           `json:"posts"`
+	Tags         map[string][]string      `json:"tags"`
+	Categories   map[string]CategoryInfo  `json:"categories"`
+	RelatedPosts map[string][]RelatedPost `json:"relatedPosts"`
+	Backlinks    map[string][]Backlink    `json:"backlinks"`
+	Series       map[string]SeriesInfo    `json:"series"`
+}
+
+// @Description Mapping of tag names to arrays of post slugs
+type TagsMap map[string][]string
+
+// @Description Mapping of category paths to category information
+type CategoriesMap map[string]CategoryInfo
+
+// @Description Mapping of post slugs to arrays of related posts
+type RelatedPostsMap map[string][]RelatedPost
+
+// @Description Mapping of post slugs to arrays of posts that link to them
+type BacklinksMap map[string][]Backlink
+
+// @Description Mapping of series slugs to series information
+type SeriesMap map[string]SeriesInfo
+
+// @Description Inverted search index mapping terms to post slugs for client-side search
+type SearchIndex map[string][]string
+
+type DefaultPostProcessor struct {
+	dateFormat string
+	cache      *cache.BuildCache
+}
+
+func NewPostProcessor(cfg *config.Config, cache *cache.BuildCache) PostProcessor {
+	return &DefaultPostProcessor{dateFormat: cfg.DateFormat, cache: cache}
+}
+
+// parseDate parses a front matter date with DATE_FORMAT. Dates that do not
+// parse sort as if published in 1970, as they do in the post listings.
+func (pp *DefaultPostProcessor) parseDate(date string) time.Time {
+	parsed, err := time.Parse(pp.dateFormat, date)
+	if err != nil {
+		return time.Unix(0, 0)
+	}
+	return parsed
+}
+
+func (pp *DefaultPostProcessor) Process(posts []content.Post) ProcessedPosts {
+	processedPosts := ProcessedPosts{
+		Posts:        make([]content.Post, 0, len(posts)),
+		Tags:         make(map[string][]string),
+		Categories:   make(map[string]CategoryInfo),
+		RelatedPosts: make(map[string][]RelatedPost),
+		Backlinks:    make(map[string][]Backlink),
+		Series:       make(map[string]SeriesInfo),
+	}
+
+	type tagInput struct {
+		Slug string
+		Tags []string
+	}
+	type categoryInput struct {
+		Slug     string
+		Category string
+	}
+	type relatedInput struct {
+		Slug        string
+		Title       string
+		Date        string
+		Tags        []string
+		ReadingTime int
+	}
+	type backlinkInput struct {
+		Slug      string
+		Title     string
+		Date      string
+		Permalink string
+		Links     []string
+	}
+	type seriesInput struct {
+		Slug   string
+		Date   string
+		Series string
+		Order  int
+	}
+
+	var tagInputs []tagInput
+	var categoryInputs []categoryInput
+	var relatedInputs []relatedInput
+	var backlinkInputs []backlinkInput
+	var seriesInputs []seriesInput
+	for _, post := range posts {
+		processedPosts.Posts = append(processedPosts.Posts, post)
+
+		tagInputs = append(tagInputs, tagInput{post.FrontMatter.Slug, post.FrontMatter.Tags})
+		categoryInputs = append(categoryInputs, categoryInput{post.FrontMatter.Slug, post.FrontMatter.Category})
+		relatedInputs = append(relatedInputs, relatedInput{
+			post.FrontMatter.Slug, post.FrontMatter.Title, post.FrontMatter.Date, post.FrontMatter.Tags, post.ReadingTime,
+		})
+		backlinkInputs = append(backlinkInputs, backlinkInput{
+			post.FrontMatter.Slug, post.FrontMatter.Title, post.FrontMatter.Date, post.Permalink, post.Links,
+		})
+		if post.FrontMatter.Series != "" {
+			seriesInputs = append(seriesInputs, seriesInput{
+				post.FrontMatter.Slug, post.FrontMatter.Date, post.FrontMatter.Series, post.FrontMatter.SeriesOrder,
+			})
+		}
+	}
+
+	tagsHash := cache.HashJSON(tagInputs)
+	if !pp.cache.LookupAggregate("tags", tagsHash, &processedPosts.Tags) {
+		pp.buildTags(posts, processedPosts.Tags)
+		_ = pp.cache.StoreAggregate("tags", tagsHash, processedPosts.Tags)
+	}
+
+	categoriesHash := cache.HashJSON(categoryInputs)
+	if !pp.cache.LookupAggregate("categories", categoriesHash, &processedPosts.Categories) {
+		for _, post := range posts {
+			if post.FrontMatter.Category != "" {
+				pp.processCategory(post.FrontMatter.Category, post.FrontMatter.Slug, processedPosts.Categories)
+			}
+		}
+		pp.buildCategoryHierarchy(processedPosts.Categories)
+		_ = pp.cache.StoreAggregate("categories", categoriesHash, processedPosts.Categories)
+	}
+
+	relatedHash := cache.HashJSON(relatedInputs)
+	if !pp.cache.LookupAggregate("related", relatedHash, &processedPosts.RelatedPosts) {
+		pp.buildRelatedPosts(posts, processedPosts.RelatedPosts)
+		_ = pp.cache.StoreAggregate("related", relatedHash, processedPosts.RelatedPosts)
+	}
+
+	backlinksHash := cache.HashJSON(backlinkInputs)
+	if !pp.cache.LookupAggregate("backlinks", backlinksHash, &processedPosts.Backlinks) {
+		pp.buildBacklinks(posts, processedPosts.Backlinks)
+		_ = pp.cache.StoreAggregate("backlinks", backlinksHash, processedPosts.Backlinks)
+	}
+
+	seriesHash := cache.HashJSON(seriesInputs)
+	if !pp.cache.LookupAggregate("series", seriesHash, &processedPosts.Series) {
+		pp.buildSeries(posts, processedPosts.Series)
+		_ = pp.cache.StoreAggregate("series", seriesHash, processedPosts.Series)
+	}
+	pp.attachSeriesNav(processedPosts.Posts, processedPosts.Series)
+
+	return processedPosts
+}
+
+// buildSeries groups posts by series, ordered by seriesOrder and then by date.
+// Posts without a seriesOrder come after those with one.
+func (pp *DefaultPostProcessor) buildSeries(posts []content.Post, series map[string]SeriesInfo) {
+	members := make(map[string][]content.Post)
+	for _, post := range posts {
+		name := strings.TrimSpace(post.FrontMatter.Series)
+		if name == "" {
+			continue
+		}
+
+		slug := content.Slugify(name)
+		if slug == "" {
+			continue
+		}
+		if _, exists := series[slug]; !exists {
+			series[slug] = SeriesInfo{Name: name, Slug: slug}
+		}
+		members[slug] = append(members[slug], post)
+	}
+
+	dates := make(map[string]time.Time)
+	for _, list := range members {
+		for _, post := range list {
+			dates[post.FrontMatter.Slug] = pp.parseDate(post.FrontMatter.Date)
+		}
+	}
+
+	for slug, list := range members {
+		sort.SliceStable(list, func(i, j int) bool {
+			a, b := list[i].FrontMatter, list[j].FrontMatter
+			if (a.SeriesOrder == 0) != (b.SeriesOrder == 0) {
+				return a.SeriesOrder != 0
+			}
+			if a.SeriesOrder != b.SeriesOrder {
+				return a.SeriesOrder < b.SeriesOrder
+			}
+			if dateA, dateB := dates[a.Slug], dates[b.Slug]; !dateA.Equal(dateB) {
+				return dateA.Before(dateB)
+			}
+			return a.Slug < b.Slug
+		})
+
+		info := series[slug]
+		for _, post := range list {
+			info.PostSlugs = append(info.PostSlugs, post.FrontMatter.Slug)
+		}
+		info.PostCount = len(info.PostSlugs)
+		series[slug] = info
+	}
+}
+
+// attachSeriesNav records on every post in a series its position and the
+// posts before and after it.
+func (pp *DefaultPostProcessor) attachSeriesNav(posts []content.Post, series map[string]SeriesInfo) {
+	index := make(map[string]int, len(posts))
+	for i, post := range posts {
+		index[post.FrontMatter.Slug] = i
+	}
+
+	summary := func(slug string) *content.PostSummary {
+		summary := content.NewPostSummary(posts[index[slug]])
+		return &summary
+	}
+
+	for _, info := range series {
+		for i, slug := range info.PostSlugs {
+			j, ok := index[slug]
+			if !ok {
+				continue
+			}
+
+			nav := &content.SeriesNav{
+				Name:  info.Name,
+				Slug:  info.Slug,
+				Index: i + 1,
+				Total: info.PostCount,
+			}
+			if i > 0 {
+				nav.Prev = summary(info.PostSlugs[i-1])
+			}
+			if i < len(info.PostSlugs)-1 {
+				nav.Next = summary(info.PostSlugs[i+1])
+			}
+			posts[j].Series = nav
+		}
+	}
+}
+
+// buildBacklinks lists, for every post, the posts that link to it, newest
+// first and by slug on the same date. Posts nobody links to get an empty list.
+func (pp *DefaultPostProcessor) buildBacklinks(posts []content.Post, backlinks map[string][]Backlink) {
+	for _, post := range posts {
+		backlinks[post.FrontMatter.Slug] = []Backlink{}
+	}
+
+	dates := make(map[string]time.Time, len(posts))
+	for _, post := range posts {
+		dates[post.FrontMatter.Slug] = pp.parseDate(post.FrontMatter.Date)
+	}
+
+	for _, post := range posts {
+		for _, slug := range post.Links {
+			if _, ok := backlinks[slug]; !ok {
+				continue
+			}
+			backlinks[slug] = append(backlinks[slug], Backlink{
+				Slug:      post.FrontMatter.Slug,
+				Title:     post.FrontMatter.Title,
+				Date:      post.FrontMatter.Date,
+				Permalink: post.Permalink,
+			})
+		}
+	}
+
+	for _, list := range backlinks {
+		sort.SliceStable(list, func(i, j int) bool {
+			if dateA, dateB := dates[list[i].Slug], dates[list[j].Slug]; !dateA.Equal(dateB) {
+				return dateA.After(dateB)
+			}
+			return list[i].Slug < list[j].Slug
+		})
+	}
+}
+
+func (pp *DefaultPostProcessor) buildTags(posts []content.Post, tags map[string][]string) {
+	for _, post := range posts {
+		for _, tag := range post.FrontMatter.Tags {
+			tags[tag] = append(tags[tag], post.FrontMatter.Slug)
+		}
+	}
+}
+
+func (pp *DefaultPostProcessor) buildRelatedPosts(posts []content.Post, relatedPosts map[string][]RelatedPost) {
+	for i, post := range posts {
+		var related []RelatedPost
+
+		for j, otherPost := range posts {
+			if i == j {
+				continue
+			}
+
+			commonTags := pp.countCommonTags(post.FrontMatter.Tags, otherPost.FrontMatter.Tags)
+			if commonTags > 0 {
+				related = append(related, RelatedPost{
+					Slug:        otherPost.FrontMatter.Slug,
+					Title:       otherPost.FrontMatter.Title,
+					Date:        otherPost.FrontMatter.Date,
+					CommonTags:  commonTags,
+					ReadingTime: otherPost.ReadingTime,
+				})
+			}
+		}
+
+		sort.Slice(related, func(i, j int) bool {
+			if related[i].CommonTags != related[j].CommonTags {
+				return related[i].CommonTags > related[j].CommonTags
+			}
+			return related[i].Date > related[j].Date
+		})
+
+		if len(related) > 5 {
+			related = related[:5]
+		}
+
+		relatedPosts[post.FrontMatter.Slug] = related
+	}
+}
+
+func (pp *DefaultPostProcessor) countCommonTags(tags1, tags2 []string) int {
+	tagMap := make(map[string]bool)
+	for _, tag := range tags1 {
+		tagMap[tag] = true
+	}
+
+	count := 0
+	for _, tag := range tags2 {
+		if tagMap[tag] {
+			count++
+		}
+	}
+	return count
+}
+
+func (pp *DefaultPostProcessor) processCategory(category string, postSlug string, categories map[string]CategoryInfo) {
+	parts := strings.Split(category, "/")
+	fullPath := ""
+
+	for i, part := range parts {
+		if i > 0 {
+			fullPath += "/"
+		}
+		fullPath += part
+
+		info, exists := categories[fullPath]
+		if !exists {
+			info = CategoryInfo{
+				Name:      part,
+				Path:      fullPath,
+				PostSlugs: []string{},
+				Children:  []string{},
+			}
+			if i > 0 {
+				parentPath := strings.Join(parts[:i], "/")
+				info.Parent = parentPath
+			}
+		}
+
+		if i == len(parts)-1 {
+			info.PostSlugs = append(info.PostSlugs, postSlug)
+		}
+		info.PostCount = len(info.PostSlugs)
+
+		categories[fullPath] = info
+	}
+}
+
+func (pp *DefaultPostProcessor) buildCategoryHierarchy(categories map[string]CategoryInfo) {
+	for path, info := range categories {
+		if info.Parent != "" {
+			if parent, exists := categories[info.Parent]; exists {
+				childExists := false
+				for _, child := range parent.Children {
+					if child == path {
+						childExists = true
+						break
+					}
+				}
+				if !childExists {
+					parent.Children = append(parent.Children, path)
+					categories[info.Parent] = parent
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package process
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tech-arch1tect/mantle/cache"
+	"github.com/tech-arch1tect/mantle/config"
+	"github.com/tech-arch1tect/mantle/content"
+)
+
+func newTestProcessor() PostProcessor {
+	cfg := config.NewConfig()
+	cfg.DateFormat = "02/01/2006"
+	return NewPostProcessor(cfg, cache.NewMemoryBuildCache(cfg))
+}
+
+func testPost(slug, date string) content.Post {
+	return content.Post{FrontMatter: content.FrontMatter{Title: slug, Slug: slug, Date: date}}
+}
+
+func TestSeriesOrder(t *testing.T) {
+	tests := []struct {
+		name  string
+		posts []content.Post
+		want  []string
+	}{
+		{
+			name: "by parsed date",
+			posts: []content.Post{
+				testPost("february", "01/02/2024"),
+				testPost("january", "15/01/2024"),
+				testPost("march", "01/03/2023"),
+			},
+			want: []string{"march", "january", "february"},
+		},
+		{
+			name: "seriesOrder first",
+			posts: []content.Post{
+				testPost("unordered", "01/01/2020"),
+				testPost("second", "01/01/2024"),
+				testPost("first", "01/01/2025"),
+			},
+			want: []string{"first", "second", "unordered"},
+		},
+		{
+			name: "same date by slug",
+			posts: []content.Post{
+				testPost("b", "01/01/2024"),
+				testPost("a", "01/01/2024"),
+			},
+			want: []string{"a", "b"},
+		},
+	}
+	for _, tt := range tests {
+		for i := range tt.posts {
+			tt.posts[i].FrontMatter.Series = "Learning Go"
+			switch tt.posts[i].FrontMatter.Slug {
+			case "first":
+				tt.posts[i].FrontMatter.SeriesOrder = 1
+			case "second":
+				tt.posts[i].FrontMatter.SeriesOrder = 2
+			}
+		}
+
+		processed := newTestProcessor().Process(tt.posts)
+
+		if got := processed.Series["learning-go"].PostSlugs; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: series order = %v, want %v", tt.name, got, tt.want)
+		}
+		for _, post := range processed.Posts {
+			nav := post.Series
+			if nav == nil || tt.want[nav.Index-1] != post.FrontMatter.Slug {
+				t.Errorf("%s: %s has series position %+v", tt.name, post.FrontMatter.Slug, nav)
+				continue
+			}
+			if nav.Index > 1 && (nav.Prev == nil || nav.Prev.Slug != tt.want[nav.Index-2]) {
+				t.Errorf("%s: %s has previous %+v, want %s", tt.name, post.FrontMatter.Slug, nav.Prev, tt.want[nav.Index-2])
+			}
+		}
+	}
+}
+
+func TestBacklinksOrder(t *testing.T) {
+	posts := []content.Post{
+		testPost("target", "01/01/2023"),
+		testPost("february", "01/02/2024"),
+		testPost("january", "15/01/2024"),
+		testPost("same-day-b", "15/01/2024"),
+		testPost("same-day-a", "15/01/2024"),
+	}
+	for i := 1; i < len(posts); i++ {
+		posts[i].Links = []string{"target"}
+	}
+
+	processed := newTestProcessor().Process(posts)
+
+	var got []string
+	for _, backlink := range processed.Backlinks["target"] {
+		got = append(got, backlink.Slug)
+	}
+	want := []string{"february", "january", "same-day-a", "same-day-b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("backlinks = %v, want %v", got, want)
+	}
+}
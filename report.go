@@ -0,0 +1,46 @@
+package mantle
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/tech-arch1tect/mantle/content"
+	"github.com/tech-arch1tect/mantle/internal/fsutil"
+)
+
+// Report is the machine-readable summary of the content issues found by a
+// build, as written by --report.
+type Report struct {
+	Strict bool            `json:"strict"`
+	Passed bool            `json:"passed"`
+	Total  int             `json:"total"`
+	Counts map[string]int  `json:"counts"`
+	Issues []content.Issue `json:"issues"`
+}
+
+// NewReport summarises issues. In strict mode any issue fails the report.
+func NewReport(issues []content.Issue, strict bool) *Report {
+	report := &Report{
+		Strict: strict,
+		Passed: !strict || len(issues) == 0,
+		Total:  len(issues),
+		Counts: make(map[string]int),
+		Issues: append([]content.Issue{}, issues...),
+	}
+	for _, issue := range issues {
+		report.Counts[issue.Kind]++
+	}
+	return report
+}
+
+// WriteFile writes the report to path as indented JSON.
+func (r *Report) WriteFile(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(path, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
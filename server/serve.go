@@ -0,0 +1,349 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tech-arch1tect/mantle/config"
+	"github.com/tech-arch1tect/mantle/output"
+)
+
+// The query parameter patterns and content types below mirror maps.conf and
+// the nginx mime.types entries for the file types Mantle generates. Bundle
+// assets fall back to the system MIME types.
+var (
+	pageParamPattern    = regexp.MustCompile(`^(\d+)$`)
+	slugParamPattern    = regexp.MustCompile(`^([a-z0-9-]+)$`)
+	yearParamPattern    = regexp.MustCompile(`^(\d{4})$`)
+	monthParamPattern   = regexp.MustCompile(`^(0?[1-9]|1[0-2])$`)
+	relatedParamPattern = regexp.MustCompile(`^([^/]+)$`)
+	nameParamPattern    = regexp.MustCompile(`^([^/]*[^/.][^/]*)$`)
+	sitemapPathPattern  = regexp.MustCompile(`^/sitemap-\d+\.xml$`)
+
+	contentTypes = map[string]string{
+		".json": "application/json",
+		".xml":  "text/xml",
+		".css":  "text/css",
+		".txt":  "text/plain",
+	}
+)
+
+// Router serves a generated public_html tree with the same routing, CORS and
+// status code semantics as the generated nginx configuration.
+type Router struct {
+	config *config.Config
+	root   fs.FS
+	logger *log.Logger
+}
+
+func NewRouter(cfg *config.Config, root fs.FS) *Router {
+	return &Router{
+		config: cfg,
+		root:   root,
+		logger: log.New(os.Stdout, "[Router] ", log.LstdFlags),
+	}
+}
+
+// SetLogger replaces the logger that every request is logged to.
+func (rt *Router) SetLogger(logger *log.Logger) {
+	rt.logger = logger
+}
+
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	rt.setSecurityHeaders(recorder)
+	rt.route(recorder, r, r.URL.Path)
+	rt.logger.Printf("%s %s %d", r.Method, r.URL.RequestURI(), recorder.status)
+}
+
+// route resolves uri the way nginx selects a location: exact matches first,
+// then the regular expression locations, then the longest prefix. Rewrites
+// re-enter route with the new uri, as "rewrite ... last" does.
+func (rt *Router) route(w http.ResponseWriter, r *http.Request, uri string) {
+	query := r.URL.Query()
+
+	switch uri {
+	case "/robots.txt", "/sitemap.xml":
+		rt.serveFile(w, r, uri, false)
+		return
+
+	case "/api/posts/by-page", "/api/previews/by-page":
+		if rt.handleCORS(w, r) {
+			return
+		}
+		if page := matchParam(pageParamPattern, query.Get("page")); page != "" {
+			rt.route(w, r, uri+"/"+page+".json")
+			return
+		}
+		rt.serveFile(w, r, uri+"/0.json", false)
+		return
+
+	case "/api/posts/by-slug", "/api/posts/toc", "/api/previews/by-slug":
+		if rt.handleCORS(w, r) {
+			return
+		}
+		slug := matchParam(slugParamPattern, query.Get("slug"))
+		if slug == "" {
+			rt.writeStatus(w, http.StatusBadRequest)
+			return
+		}
+		rt.route(w, r, uri+"/"+slug+".json")
+		return
+
+	case "/api/tags", "/api/categories":
+		if rt.handleCORS(w, r) {
+			return
+		}
+		param := "tag"
+		if uri == "/api/categories" {
+			param = "category"
+		}
+		if value := matchParam(nameParamPattern, query.Get(param)); value != "" {
+			rt.route(w, r, uri+"/"+value+".json")
+			return
+		}
+		rt.serveFile(w, r, uri+"/all.json", false)
+		return
+
+	case "/api/tags/feed", "/api/categories/feed":
+		if rt.handleCORS(w, r) {
+			return
+		}
+		param := "tag"
+		if uri == "/api/categories/feed" {
+			param = "category"
+		}
+		value := matchParam(nameParamPattern, query.Get(param))
+		if value == "" {
+			rt.writeStatus(w, http.StatusBadRequest)
+			return
+		}
+		rt.route(w, r, path.Dir(uri)+"/"+value+"/feed.xml")
+		return
+
+	case "/api/related":
+		if rt.handleCORS(w, r) {
+			return
+		}
+		if slug := matchParam(relatedParamPattern, query.Get("slug")); slug != "" {
+			rt.route(w, r, uri+"/"+slug+".json")
+			return
+		}
+		rt.serveFile(w, r, uri+"/all.json", false)
+		return
+
+	case "/api/backlinks":
+		if rt.handleCORS(w, r) {
+			return
+		}
+		if slug := matchParam(slugParamPattern, query.Get("slug")); slug != "" {
+			rt.route(w, r, uri+"/"+slug+".json")
+			return
+		}
+		rt.serveFile(w, r, uri+"/all.json", false)
+		return
+
+	case "/api/series":
+		if rt.handleCORS(w, r) {
+			return
+		}
+		if series := matchParam(slugParamPattern, query.Get("series")); series != "" {
+			rt.route(w, r, uri+"/"+series+".json")
+			return
+		}
+		rt.serveFile(w, r, uri+"/all.json", false)
+		return
+
+	case "/api/archives":
+		if rt.handleCORS(w, r) {
+			return
+		}
+		year := matchParam(yearParamPattern, query.Get("year"))
+		if year == "" {
+			rt.serveFile(w, r, uri+"/index.json", false)
+			return
+		}
+		resource := uri + "/" + year
+		if month := matchParam(monthParamPattern, query.Get("month")); month != "" {
+			if len(month) == 1 {
+				month = "0" + month
+			}
+			resource += "/" + month
+		}
+		if page := matchParam(pageParamPattern, query.Get("page")); page != "" {
+			resource += "/by-page/" + page
+		}
+		rt.route(w, r, resource+".json")
+		return
+	}
+
+	switch {
+	case strings.HasSuffix(uri, ".json"):
+		if rt.handleCORS(w, r) {
+			return
+		}
+		rt.serveFile(w, r, uri, true)
+
+	case sitemapPathPattern.MatchString(uri):
+		rt.serveFile(w, r, uri, false)
+
+	case strings.HasPrefix(uri, "/api/"), strings.HasPrefix(uri, "/media/"):
+		if rt.handleCORS(w, r) {
+			return
+		}
+		rt.serveFile(w, r, uri, false)
+
+	default:
+		rt.writeStatus(w, http.StatusNotFound)
+	}
+}
+
+// handleCORS adds the headers from cors.conf and answers preflight requests.
+// It reports whether the request has been fully handled.
+func (rt *Router) handleCORS(w http.ResponseWriter, r *http.Request) bool {
+	header := w.Header()
+	header.Set("Access-Control-Allow-Origin", rt.config.CorsAllowOrigin)
+	header.Set("Access-Control-Allow-Methods", rt.config.CorsAllowMethods)
+	header.Set("Access-Control-Allow-Headers", rt.config.CorsAllowHeaders)
+
+	if r.Method != http.MethodOptions {
+		return false
+	}
+
+	header.Set("Access-Control-Max-Age", strconv.Itoa(rt.config.CorsMaxAge))
+	header.Set("Content-Type", "text/plain")
+	header.Set("Content-Length", "0")
+	w.WriteHeader(http.StatusNoContent)
+	return true
+}
+
+func (rt *Router) setSecurityHeaders(w http.ResponseWriter) {
+	header := w.Header()
+	header.Set("X-Frame-Options", "SAMEORIGIN")
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("X-XSS-Protection", "1; mode=block")
+	header.Set("Referrer-Policy", "no-referrer-when-downgrade")
+}
+
+// serveFile serves uri from the root filesystem like the nginx static
+// handler: directories are forbidden since autoindex is off, and only GET and
+// HEAD are allowed. JSON responses from json.conf also carry Cache-Control.
+func (rt *Router) serveFile(w http.ResponseWriter, r *http.Request, uri string, jsonLocation bool) {
+	name := strings.TrimPrefix(path.Clean(uri), "/")
+	if !fs.ValidPath(name) {
+		rt.writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	file, err := rt.root.Open(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			rt.writeStatus(w, http.StatusNotFound)
+			return
+		}
+		rt.writeStatus(w, http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		rt.writeStatus(w, http.StatusInternalServerError)
+		return
+	}
+	if info.IsDir() {
+		rt.writeStatus(w, http.StatusForbidden)
+		return
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		rt.writeStatus(w, http.StatusMethodNotAllowed)
+		return
+	}
+
+	content, ok := file.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			rt.writeStatus(w, http.StatusInternalServerError)
+			return
+		}
+		content = bytes.NewReader(data)
+	}
+
+	header := w.Header()
+	contentType, ok := contentTypes[path.Ext(name)]
+	if !ok {
+		contentType = mime.TypeByExtension(path.Ext(name))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	header.Set("Content-Type", contentType)
+	header.Set("ETag", etag(info))
+	if jsonLocation {
+		header.Set("Cache-Control", "public, max-age=300")
+	}
+
+	http.ServeContent(w, r, name, info.ModTime(), content)
+}
+
+// etag derives an entity tag for a file. In-memory files tag their content
+// hash; files on disk use the modification time and size, as nginx does.
+func etag(info fs.FileInfo) string {
+	if hash, ok := info.Sys().(output.ContentHash); ok && hash != "" {
+		return `"` + string(hash) + `"`
+	}
+	return fmt.Sprintf(`"%x-%x"`, info.ModTime().Unix(), info.Size())
+}
+
+func (rt *Router) writeStatus(w http.ResponseWriter, status int) {
+	w.Header().Del("Cache-Control")
+	http.Error(w, fmt.Sprintf("%d %s", status, http.StatusText(status)), status)
+}
+
+func matchParam(pattern *regexp.Regexp, value string) string {
+	match := pattern.FindStringSubmatch(value)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sr *statusRecorder) WriteHeader(status int) {
+	sr.status = status
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+// ListenAndServe runs server until ctx is cancelled.
+func ListenAndServe(ctx context.Context, server *http.Server) error {
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/tech-arch1tect/mantle/config"
+)
+
+func newTestRouter() *Router {
+	files := fstest.MapFS{
+		"api/posts/by-page/0.json":              {Data: []byte(`"page 0"`)},
+		"api/posts/by-page/2.json":              {Data: []byte(`"page 2"`)},
+		"api/posts/by-slug/hello.json":          {Data: []byte(`"hello"`)},
+		"api/tags/go/feed.xml":                  {Data: []byte(`<rss/>`)},
+		"api/archives/index.json":               {Data: []byte(`"archives"`)},
+		"api/archives/2024.json":                {Data: []byte(`"2024"`)},
+		"api/archives/2024/03.json":             {Data: []byte(`"2024-03"`)},
+		"api/archives/2024/03/by-page/1.json":   {Data: []byte(`"2024-03 page 1"`)},
+		"media/hello/photo.png":                 {Data: []byte("png")},
+		"api/categories/all.json":               {Data: []byte(`{}`)},
+		"api/tags/all.json":                     {Data: []byte(`"tags"`)},
+		"api/categories/tech/nested/ignore.txt": {Data: []byte("")},
+	}
+	rt := NewRouter(config.NewConfig(), files)
+	rt.SetLogger(log.New(io.Discard, "", 0))
+	return rt
+}
+
+func TestRouter(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		status int
+		body   string
+	}{
+		{"by-page default", "GET", "/api/posts/by-page", 200, `"page 0"`},
+		{"by-page rewrite", "GET", "/api/posts/by-page?page=2", 200, `"page 2"`},
+		{"by-page invalid page", "GET", "/api/posts/by-page?page=x", 200, `"page 0"`},
+		{"by-page missing page", "GET", "/api/posts/by-page?page=9", 404, ""},
+		{"by-slug", "GET", "/api/posts/by-slug?slug=hello", 200, `"hello"`},
+		{"by-slug without slug", "GET", "/api/posts/by-slug", 400, ""},
+		{"by-slug invalid slug", "GET", "/api/posts/by-slug?slug=../x", 400, ""},
+		{"toc without slug", "GET", "/api/posts/toc", 400, ""},
+		{"tag feed", "GET", "/api/tags/feed?tag=go", 200, `<rss/>`},
+		{"tag feed without tag", "GET", "/api/tags/feed", 400, ""},
+		{"category feed without category", "GET", "/api/categories/feed", 400, ""},
+		{"tag feed of parent directory", "GET", "/api/tags/feed?tag=..", 400, ""},
+		{"tag feed with slash", "GET", "/api/tags/feed?tag=a/b", 400, ""},
+		{"tag with slash", "GET", "/api/tags?tag=../categories/all", 200, `"tags"`},
+		{"archive index", "GET", "/api/archives", 200, `"archives"`},
+		{"archive year", "GET", "/api/archives?year=2024", 200, `"2024"`},
+		{"archive month padded", "GET", "/api/archives?year=2024&month=3", 200, `"2024-03"`},
+		{"archive month", "GET", "/api/archives?year=2024&month=03&page=1", 200, `"2024-03 page 1"`},
+		{"archive invalid month", "GET", "/api/archives?year=2024&month=13", 200, `"2024"`},
+		{"directory", "GET", "/api/categories/tech", 403, ""},
+		{"media", "GET", "/media/hello/photo.png", 200, "png"},
+		{"post", "POST", "/api/posts/by-slug?slug=hello", 405, ""},
+		{"delete", "DELETE", "/media/hello/photo.png", 405, ""},
+		{"unknown", "GET", "/index.html", 404, ""},
+	}
+	rt := newTestRouter()
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		rt.ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.target, nil))
+
+		if recorder.Code != tt.status {
+			t.Errorf("%s: %s %s = %d, want %d", tt.name, tt.method, tt.target, recorder.Code, tt.status)
+			continue
+		}
+		if tt.body != "" && recorder.Body.String() != tt.body {
+			t.Errorf("%s: body = %q, want %q", tt.name, recorder.Body.String(), tt.body)
+		}
+		if recorder.Header().Get("X-Content-Type-Options") != "nosniff" {
+			t.Errorf("%s: security headers missing", tt.name)
+		}
+	}
+}
+
+func TestRouterCORSPreflight(t *testing.T) {
+	rt := newTestRouter()
+	for _, target := range []string{"/api/posts/by-page", "/api/posts/by-slug", "/api/archives", "/api/posts/by-page/0.json"} {
+		recorder := httptest.NewRecorder()
+		rt.ServeHTTP(recorder, httptest.NewRequest(http.MethodOptions, target, nil))
+
+		if recorder.Code != http.StatusNoContent {
+			t.Errorf("OPTIONS %s = %d, want 204", target, recorder.Code)
+		}
+		header := recorder.Header()
+		if header.Get("Access-Control-Allow-Origin") != rt.config.CorsAllowOrigin || header.Get("Access-Control-Max-Age") == "" {
+			t.Errorf("OPTIONS %s: CORS headers = %v", target, header)
+		}
+	}
+}
+
+func TestRouterETag(t *testing.T) {
+	rt := newTestRouter()
+
+	recorder := httptest.NewRecorder()
+	rt.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/posts/by-slug?slug=hello", nil))
+	tag := recorder.Header().Get("ETag")
+	if recorder.Code != http.StatusOK || !strings.HasPrefix(tag, `"`) {
+		t.Fatalf("GET = %d with ETag %q, want 200 with a quoted ETag", recorder.Code, tag)
+	}
+	if cacheControl := recorder.Header().Get("Cache-Control"); cacheControl != "public, max-age=300" {
+		t.Errorf("Cache-Control = %q, want the json.conf value", cacheControl)
+	}
+
+	tests := []struct {
+		ifNoneMatch string
+		status      int
+	}{
+		{tag, http.StatusNotModified},
+		{`"other"`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		request := httptest.NewRequest(http.MethodGet, "/api/posts/by-slug?slug=hello", nil)
+		request.Header.Set("If-None-Match", tt.ifNoneMatch)
+		recorder := httptest.NewRecorder()
+		rt.ServeHTTP(recorder, request)
+		if recorder.Code != tt.status {
+			t.Errorf("If-None-Match %s = %d, want %d", tt.ifNoneMatch, recorder.Code, tt.status)
+		}
+	}
+}
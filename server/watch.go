@@ -0,0 +1,333 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/tech-arch1tect/mantle/cache"
+	"github.com/tech-arch1tect/mantle/config"
+	"github.com/tech-arch1tect/mantle/process"
+)
+
+// ContentWatcher reports changes to CONTENT_DIR. It uses filesystem
+// notifications where available and falls back to polling, which also works
+// on bind mounts and network filesystems that do not deliver events.
+type ContentWatcher struct {
+	dir          string
+	poll         bool
+	pollInterval time.Duration
+	debounce     time.Duration
+	logger       *log.Logger
+}
+
+func NewContentWatcher(cfg *config.Config, poll bool, pollInterval, debounce time.Duration) *ContentWatcher {
+	return &ContentWatcher{
+		dir:          cfg.ContentDir,
+		poll:         poll,
+		pollInterval: pollInterval,
+		debounce:     debounce,
+		logger:       log.New(os.Stdout, "[ContentWatcher] ", log.LstdFlags),
+	}
+}
+
+// Watch calls onChange once changes have settled for the debounce period, and
+// blocks until ctx is cancelled. Changes made while onChange is running are
+// picked up by a further call.
+func (cw *ContentWatcher) Watch(ctx context.Context, onChange func()) error {
+	changes := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changes <- struct{}{}:
+		default:
+		}
+	}
+
+	if cw.poll {
+		go cw.pollChanges(ctx, notify)
+	} else if err := cw.startNotify(ctx, notify); err != nil {
+		cw.logger.Printf("Filesystem notifications unavailable (%v), falling back to polling", err)
+		go cw.pollChanges(ctx, notify)
+	}
+
+	var timer *time.Timer
+	var fire <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return nil
+		case <-changes:
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.NewTimer(cw.debounce)
+			fire = timer.C
+		case <-fire:
+			fire = nil
+			onChange()
+		}
+	}
+}
+
+func (cw *ContentWatcher) startNotify(ctx context.Context, notify func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	if err := cw.addRecursive(watcher, cw.dir); err != nil {
+		watcher.Close()
+		return err
+	}
+	cw.logger.Printf("Watching %s for changes", cw.dir)
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op == fsnotify.Chmod {
+					continue
+				}
+				if event.Op.Has(fsnotify.Create) {
+					if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+						if err := cw.addRecursive(watcher, event.Name); err != nil {
+							cw.logger.Printf("Failed to watch %s: %v", event.Name, err)
+						}
+					}
+				}
+				notify()
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				cw.logger.Printf("Watch error: %v", err)
+			}
+		}
+	}()
+	return nil
+}
+
+func (cw *ContentWatcher) addRecursive(watcher *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if err := watcher.Add(path); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+func (cw *ContentWatcher) pollChanges(ctx context.Context, notify func()) {
+	cw.logger.Printf("Polling %s for changes every %s", cw.dir, cw.pollInterval)
+
+	previous := cw.snapshot()
+	ticker := time.NewTicker(cw.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			current := cw.snapshot()
+			if !sameSnapshot(previous, current) {
+				notify()
+			}
+			previous = current
+		}
+	}
+}
+
+func (cw *ContentWatcher) snapshot() map[string]fileState {
+	files := make(map[string]fileState)
+	_ = filepath.WalkDir(cw.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			files[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+		}
+		return nil
+	})
+	return files
+}
+
+func sameSnapshot(a, b map[string]fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, state := range a {
+		other, ok := b[path]
+		if !ok || !other.modTime.Equal(state.modTime) || other.size != state.size {
+			return false
+		}
+	}
+	return true
+}
+
+// RebuildEvent is sent to live-reload clients after a successful rebuild.
+type RebuildEvent struct {
+	Changed []string `json:"changed"`
+	Removed []string `json:"removed"`
+}
+
+// BuildErrorEvent is sent to live-reload clients when a rebuild fails.
+type BuildErrorEvent struct {
+	Error string `json:"error"`
+}
+
+// PostSignatures maps each post slug to a hash of its generated content.
+func PostSignatures(processedPosts process.ProcessedPosts) map[string]string {
+	signatures := make(map[string]string, len(processedPosts.Posts))
+	for _, post := range processedPosts.Posts {
+		signatures[post.FrontMatter.Slug] = cache.HashJSON(post)
+	}
+	return signatures
+}
+
+// DiffSignatures compares two sets of PostSignatures.
+func DiffSignatures(previous, current map[string]string) RebuildEvent {
+	event := RebuildEvent{Changed: []string{}, Removed: []string{}}
+	for slug, signature := range current {
+		if previous[slug] != signature {
+			event.Changed = append(event.Changed, slug)
+		}
+	}
+	for slug := range previous {
+		if _, ok := current[slug]; !ok {
+			event.Removed = append(event.Removed, slug)
+		}
+	}
+	sort.Strings(event.Changed)
+	sort.Strings(event.Removed)
+	return event
+}
+
+// EventBroker fans Server-Sent Events out to every connected client.
+type EventBroker struct {
+	config  *config.Config
+	logger  *log.Logger
+	mu      sync.Mutex
+	clients map[chan []byte]struct{}
+}
+
+func NewEventBroker(cfg *config.Config) *EventBroker {
+	return &EventBroker{
+		config:  cfg,
+		logger:  log.New(os.Stdout, "[EventBroker] ", log.LstdFlags),
+		clients: make(map[chan []byte]struct{}),
+	}
+}
+
+func (eb *EventBroker) Publish(event string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", event, err)
+	}
+	message := []byte(fmt.Sprintf("event: %s\ndata: %s\n\n", event, data))
+
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	for client := range eb.clients {
+		select {
+		case client <- message:
+		default:
+			// Slow clients miss events rather than holding up rebuilds.
+		}
+	}
+	eb.logger.Printf("Sent %s event to %d client(s)", event, len(eb.clients))
+	return nil
+}
+
+func (eb *EventBroker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	header := w.Header()
+	header.Set("Access-Control-Allow-Origin", eb.config.CorsAllowOrigin)
+	header.Set("Access-Control-Allow-Methods", eb.config.CorsAllowMethods)
+	header.Set("Access-Control-Allow-Headers", eb.config.CorsAllowHeaders)
+
+	switch r.Method {
+	case http.MethodOptions:
+		header.Set("Access-Control-Max-Age", strconv.Itoa(eb.config.CorsMaxAge))
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case http.MethodGet:
+	default:
+		http.Error(w, "405 Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "500 Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+
+	client := make(chan []byte, 8)
+	eb.mu.Lock()
+	eb.clients[client] = struct{}{}
+	eb.mu.Unlock()
+	defer func() {
+		eb.mu.Lock()
+		delete(eb.clients, client)
+		eb.mu.Unlock()
+	}()
+
+	if _, err := fmt.Fprint(w, ": connected\n\n"); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(15 * time.Second)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case message := <-client:
+			if _, err := w.Write(message); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
@@ -1,12 +1,15 @@
-package main
+package mantle
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 
 	"github.com/swaggo/swag"
 	"github.com/swaggo/swag/gen"
+	"github.com/tech-arch1tect/mantle/config"
+	"github.com/tech-arch1tect/mantle/output"
 )
 
 // @title           Mantle API
@@ -22,8 +25,8 @@ import (
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number (0-indexed)"
-// @Success 200 {object} PostsResponse "Paginated posts"
-// @Failure 404 {object} ErrorResponse "Page not found"
+// @Success 200 {object} output.PostsResponse "Paginated posts"
+// @Failure 404 {object} output.ErrorResponse "Page not found"
 // @Router /posts/by-page [get]
 func GetPostsByPage() {}
 
@@ -33,20 +36,32 @@ func GetPostsByPage() {}
 // @Accept json
 // @Produce json
 // @Param slug query string true "Post slug"
-// @Success 200 {object} Post "Single post"
-// @Failure 400 {object} ErrorResponse "Missing slug parameter"
-// @Failure 404 {object} ErrorResponse "Post not found"
+// @Success 200 {object} content.Post "Single post"
+// @Failure 400 {object} output.ErrorResponse "Missing slug parameter"
+// @Failure 404 {object} output.ErrorResponse "Post not found"
 // @Router /posts/by-slug [get]
 func GetPostBySlug() {}
 
+// @Summary Get post table of contents
+// @Description Get the heading outline of a specific post by its slug
+// @Tags posts
+// @Accept json
+// @Produce json
+// @Param slug query string true "Post slug"
+// @Success 200 {array} content.TOCEntry "Table of contents"
+// @Failure 400 {object} output.ErrorResponse "Missing slug parameter"
+// @Failure 404 {object} output.ErrorResponse "Post not found"
+// @Router /posts/toc [get]
+func GetPostTOC() {}
+
 // @Summary Get paginated previews
 // @Description Get paginated post previews with optional page parameter
 // @Tags previews
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number (0-indexed)"
-// @Success 200 {object} PreviewsResponse "Paginated previews"
-// @Failure 404 {object} ErrorResponse "Page not found"
+// @Success 200 {object} output.PreviewsResponse "Paginated previews"
+// @Failure 404 {object} output.ErrorResponse "Page not found"
 // @Router /previews/by-page [get]
 func GetPreviewsByPage() {}
 
@@ -56,9 +71,9 @@ func GetPreviewsByPage() {}
 // @Accept json
 // @Produce json
 // @Param slug query string true "Post slug"
-// @Success 200 {object} PostPreview "Single preview"
-// @Failure 400 {object} ErrorResponse "Missing slug parameter"
-// @Failure 404 {object} ErrorResponse "Preview not found"
+// @Success 200 {object} content.PostPreview "Single preview"
+// @Failure 400 {object} output.ErrorResponse "Missing slug parameter"
+// @Failure 404 {object} output.ErrorResponse "Preview not found"
 // @Router /previews/by-slug [get]
 func GetPreviewBySlug() {}
 
@@ -68,9 +83,9 @@ func GetPreviewBySlug() {}
 // @Accept json
 // @Produce json
 // @Param tag query string false "Tag name"
-// @Success 200 {object} TagsMap "All tags (used for /api/tags)"
-// @Success 200 {array} PostPreview "Previews for specific tag (when ?tag=...)"
-// @Failure 404 {object} ErrorResponse "Tag not found"
+// @Success 200 {object} process.TagsMap "All tags (used for /api/tags)"
+// @Success 200 {array} content.PostPreview "Previews for specific tag (when ?tag=...)"
+// @Failure 404 {object} output.ErrorResponse "Tag not found"
 // @Router /tags [get]
 func GetTags() {}
 
@@ -80,9 +95,9 @@ func GetTags() {}
 // @Accept json
 // @Produce json
 // @Param category query string false "Category path (e.g., tech_tutorials)"
-// @Success 200 {object} CategoriesMap "All categories (used for /api/categories)"
-// @Success 200 {array} PostPreview "Previews for a specific category (when ?category=...)"
-// @Failure 404 {object} ErrorResponse "Category not found"
+// @Success 200 {object} process.CategoriesMap "All categories (used for /api/categories)"
+// @Success 200 {array} content.PostPreview "Previews for a specific category (when ?category=...)"
+// @Failure 404 {object} output.ErrorResponse "Category not found"
 // @Router /categories [get]
 func GetCategories() {}
 
@@ -91,7 +106,7 @@ func GetCategories() {}
 // @Tags categories
 // @Accept json
 // @Produce json
-// @Success 200 {object} CategoryTree "Hierarchical category tree"
+// @Success 200 {object} output.CategoryTree "Hierarchical category tree"
 // @Router /categories/tree.json [get]
 func GetCategoryTree() {}
 
@@ -101,51 +116,150 @@ func GetCategoryTree() {}
 // @Accept json
 // @Produce json
 // @Param slug query string false "Post slug"
-// @Success 200 {object} RelatedPostsMap "All related posts mapping"
-// @Success 200 {array} RelatedPost "Related posts for specific post when slug provided"
-// @Failure 404 {object} ErrorResponse "Post not found"
+// @Success 200 {object} process.RelatedPostsMap "All related posts mapping"
+// @Success 200 {array} process.RelatedPost "Related posts for specific post when slug provided"
+// @Failure 404 {object} output.ErrorResponse "Post not found"
 // @Router /related [get]
 func GetRelated() {}
 
+// @Summary Get backlinks
+// @Description Get the posts that link to each post, or to a specific post
+// @Tags backlinks
+// @Accept json
+// @Produce json
+// @Param slug query string false "Post slug"
+// @Success 200 {object} process.BacklinksMap "All backlinks mapping"
+// @Success 200 {array} process.Backlink "Posts linking to a specific post when slug provided"
+// @Failure 404 {object} output.ErrorResponse "Post not found"
+// @Router /backlinks [get]
+func GetBacklinks() {}
+
+// @Summary Get series
+// @Description Get all series, or the posts of a specific series in reading order
+// @Tags series
+// @Accept json
+// @Produce json
+// @Param series query string false "Series slug"
+// @Success 200 {object} process.SeriesMap "All series mapping"
+// @Success 200 {array} content.PostPreview "Posts of a specific series when series provided"
+// @Failure 404 {object} output.ErrorResponse "Series not found"
+// @Router /series [get]
+func GetSeries() {}
+
+// @Summary Get archives
+// @Description Get the years and months with posts, or paginated previews of the posts from a year or month
+// @Tags archives
+// @Accept json
+// @Produce json
+// @Param year query int false "Four-digit year"
+// @Param month query int false "Month, from 1 to 12 (requires year)"
+// @Param page query int false "Page number (0-based)"
+// @Success 200 {object} output.ArchiveIndex "Years and months with post counts"
+// @Success 200 {object} output.PreviewsResponse "Previews of the posts from a year or month when year provided"
+// @Failure 404 {object} output.ErrorResponse "No posts from that year or month"
+// @Router /archives [get]
+func GetArchives() {}
+
 // @Summary Get search index
 // @Description Get inverted search index for client-side search
 // @Tags search
 // @Accept json
 // @Produce json
-// @Success 200 {object} SearchIndex "Inverted search index mapping terms to post slugs"
+// @Success 200 {object} process.SearchIndex "Inverted search index mapping terms to post slugs"
 // @Router /search/inverted.json [get]
 func GetSearchIndex() {}
 
+// @Summary Get RSS feed
+// @Description Get the RSS 2.0 feed of the most recent posts
+// @Tags feeds
+// @Produce xml
+// @Success 200 {string} string "RSS 2.0 document"
+// @Router /feed.xml [get]
+func GetRSSFeed() {}
+
+// @Summary Get Atom feed
+// @Description Get the Atom feed of the most recent posts
+// @Tags feeds
+// @Produce xml
+// @Success 200 {string} string "Atom document"
+// @Router /atom.xml [get]
+func GetAtomFeed() {}
+
+// @Summary Get JSON Feed
+// @Description Get the JSON Feed 1.1 document of the most recent posts
+// @Tags feeds
+// @Produce json
+// @Success 200 {object} object "JSON Feed 1.1 document"
+// @Router /feed.json [get]
+func GetJSONFeed() {}
+
+// @Summary Get tag feed
+// @Description Get the RSS 2.0 feed of the most recent posts with a specific tag
+// @Tags feeds
+// @Produce xml
+// @Param tag query string true "Tag name"
+// @Success 200 {string} string "RSS 2.0 document"
+// @Failure 400 {object} output.ErrorResponse "Missing tag parameter"
+// @Failure 404 {object} output.ErrorResponse "Tag not found"
+// @Router /tags/feed [get]
+func GetTagFeed() {}
+
+// @Summary Get category feed
+// @Description Get the RSS 2.0 feed of the most recent posts in a specific category
+// @Tags feeds
+// @Produce xml
+// @Param category query string true "Category path (e.g., tech_tutorials)"
+// @Success 200 {string} string "RSS 2.0 document"
+// @Failure 400 {object} output.ErrorResponse "Missing category parameter"
+// @Failure 404 {object} output.ErrorResponse "Category not found"
+// @Router /categories/feed [get]
+func GetCategoryFeed() {}
+
 // @Summary Get API metadata
 // @Description Get unified API metadata including counts, pagination info, and configuration
 // @Tags metadata
 // @Accept json
 // @Produce json
-// @Success 200 {object} MetadataResponse "API metadata"
+// @Success 200 {object} output.MetadataResponse "API metadata"
 // @Router /meta.json [get]
 func GetMetadata() {}
 
 type SwaggerGenerator struct {
-	config *Config
+	config *config.Config
 	logger *log.Logger
+	writer *output.OutputWriter
 }
 
-func NewSwaggerGenerator(config *Config) *SwaggerGenerator {
+// NewSwaggerGenerator creates a generator for the OpenAPI specification. When
+// writer is nil the specification is written straight to the output directory
+// without being recorded in the build manifest.
+func NewSwaggerGenerator(cfg *config.Config, writer *output.OutputWriter) *SwaggerGenerator {
 	return &SwaggerGenerator{
-		config: config,
+		config: cfg,
 		logger: log.New(os.Stdout, "[SwaggerGenerator] ", log.LstdFlags),
+		writer: writer,
 	}
 }
 
 func (sg *SwaggerGenerator) Generate() error {
 	sg.logger.Println("Generating OpenAPI specification...")
 
-	config := &gen.Config{
+	outputDir := filepath.Join(sg.config.OutputDir, "public_html", "api")
+	if sg.writer != nil {
+		tmpDir, err := os.MkdirTemp("", "mantle-openapi-")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		defer os.RemoveAll(tmpDir)
+		outputDir = tmpDir
+	}
+
+	genConfig := &gen.Config{
 		SearchDir:          ".",
 		Excludes:           "",
 		MainAPIFile:        "swagger.go",
 		PropNamingStrategy: swag.CamelCase,
-		OutputDir:          filepath.Join(sg.config.OutputDir, "public_html", "api"),
+		OutputDir:          outputDir,
 		OutputTypes:        []string{"json", "yaml"},
 		ParseVendor:        false,
 		ParseDependency:    0,
@@ -157,10 +271,23 @@ func (sg *SwaggerGenerator) Generate() error {
 		InstanceName:       "",
 	}
 
-	if err := gen.New().Build(config); err != nil {
+	if err := gen.New().Build(genConfig); err != nil {
 		return err
 	}
 
+	if sg.writer != nil {
+		for _, outputType := range genConfig.OutputTypes {
+			filename := "swagger." + outputType
+			data, err := os.ReadFile(filepath.Join(outputDir, filename))
+			if err != nil {
+				return fmt.Errorf("failed to read generated %s: %w", filename, err)
+			}
+			if err := sg.writer.Write(filepath.Join(sg.config.OutputDir, "public_html", "api", filename), data); err != nil {
+				return err
+			}
+		}
+	}
+
 	sg.logger.Println("OpenAPI specification generated successfully")
 	return nil
 }